@@ -0,0 +1,471 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// blockingFrames maps runtime and sync functions a goroutine blocks in to the
+// argument holding what it waits on, and the kind of that resource
+var blockingFrames = map[string]struct{ arg, kind string }{
+	"runtime.chanrecv":                {"c", "chan"},
+	"runtime.chansend":                {"c", "chan"},
+	"sync.(*Mutex).Lock":              {"m", "mutex"},
+	"sync.(*Mutex).lockSlow":          {"m", "mutex"},
+	"internal/sync.(*Mutex).Lock":     {"m", "mutex"},
+	"internal/sync.(*Mutex).lockSlow": {"m", "mutex"},
+	"sync.(*RWMutex).Lock":            {"rw", "rwmutex"},
+	"sync.(*RWMutex).RLock":           {"rw", "rwmutex"},
+	"sync.(*WaitGroup).Wait":          {"wg", "waitgroup"},
+	"sync.(*Cond).Wait":               {"c", "cond"},
+}
+
+// maxAnalyzedStacks bounds how many blocked goroutines get their variables loaded
+const maxAnalyzedStacks = 200
+
+// maxSuspects bounds the deadlock participants reported with stacks
+const maxSuspects = 10
+
+// blockedGoroutine is a blocked goroutine with what it waits on and what its
+// own frames refer to, which may include resources it holds
+type blockedGoroutine struct {
+	goroutine  types.Goroutine
+	waitSince  int64
+	resource   string   // "chan 0xc000010000"; empty when it cannot be determined
+	references []string // Resources reachable from its non-runtime frames
+	stack      []string
+}
+
+// AnalyzeGoroutines groups blocked goroutines by wait reason and location,
+// looks for wait cycles over channels and mutexes, and flags goroutines
+// blocked for longer than minBlocked
+func (c *Client) AnalyzeGoroutines(minBlocked time.Duration) types.AnalyzeGoroutinesResponse {
+	if c.client == nil {
+		return c.createAnalyzeGoroutinesResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return c.createAnalyzeGoroutinesResponse(nil, nil, c.exitedError())
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createAnalyzeGoroutinesResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	goroutines, err := c.allGoroutines()
+	if err != nil {
+		return c.createAnalyzeGoroutinesResponse(state, nil, err)
+	}
+
+	reasons := c.waitReasons()
+	analysis := &types.GoroutineAnalysis{Total: len(goroutines), AllBlocked: true}
+	var blocked []*blockedGoroutine
+	for _, g := range goroutines {
+		if !isBlocked(g) {
+			if !isRuntimeGoroutine(g) {
+				analysis.AllBlocked = false
+			}
+			continue
+		}
+		if isRuntimeGoroutine(g) {
+			continue
+		}
+		blocked = append(blocked, &blockedGoroutine{goroutine: convertGoroutine(g, state, reasons), waitSince: g.WaitSince})
+	}
+	analysis.Blocked = len(blocked)
+	if len(blocked) == 0 {
+		analysis.AllBlocked = false
+	}
+
+	for i, b := range blocked {
+		if i == maxAnalyzedStacks {
+			logger.Debug("Only analyzing the stacks of the first %d blocked goroutines", maxAnalyzedStacks)
+			break
+		}
+		c.inspectBlocked(b)
+	}
+
+	analysis.Groups = groupBlocked(blocked)
+
+	now := c.runtimeNow(blocked)
+	for _, b := range blocked {
+		if b.waitSince <= 0 || now <= 0 {
+			continue
+		}
+		if d := time.Duration(now - b.waitSince); d >= minBlocked {
+			analysis.LongBlocked = append(analysis.LongBlocked, blockedInfo(b, d))
+		}
+	}
+	sort.Slice(analysis.LongBlocked, func(i, j int) bool {
+		return analysis.LongBlocked[i].BlockedNanos > analysis.LongBlocked[j].BlockedNanos
+	})
+
+	analysis.Deadlocks = findWaitCycles(blocked)
+	analysis.Suspects = deadlockSuspects(blocked, analysis)
+
+	return c.createAnalyzeGoroutinesResponse(state, analysis, nil)
+}
+
+// isBlocked reports whether a goroutine is parked waiting for something
+func isBlocked(g *api.Goroutine) bool {
+	return g.Status == api.GoroutineWaiting
+}
+
+// isRuntimeGoroutine reports whether a goroutine was started by the runtime
+// itself, such as GC workers and the finalizer goroutine
+func isRuntimeGoroutine(g *api.Goroutine) bool {
+	if g.StartLoc.Function == nil {
+		return false
+	}
+	name := g.StartLoc.Function.Name()
+	return strings.HasPrefix(name, "runtime.") && name != "runtime.main"
+}
+
+// inspectBlocked finds the resource a goroutine waits on and the resources its
+// own frames refer to, from its stack with variables loaded
+func (c *Client) inspectBlocked(b *blockedGoroutine) {
+	cfg := &api.LoadConfig{
+		FollowPointers:     true,
+		MaxVariableRecurse: 2,
+		MaxStructFields:    -1,
+	}
+	frames, err := c.client.Stacktrace(b.goroutine.ID, 30, 0, cfg)
+	if err != nil {
+		logger.Debug("Warning: Failed to get stack of goroutine %d: %v", b.goroutine.ID, err)
+		return
+	}
+
+	for _, frame := range frames {
+		b.stack = append(b.stack, formatFrame(frame))
+	}
+	b.resource = waitedResource(frames)
+
+	seen := make(map[string]bool)
+	for _, frame := range frames {
+		if frame.Function == nil || !isUserFunction(frame.Function.Name()) {
+			continue
+		}
+		for _, vars := range [][]api.Variable{frame.Arguments, frame.Locals} {
+			for i := range vars {
+				collectAddresses(&vars[i], seen)
+			}
+		}
+	}
+	for addr := range seen {
+		b.references = append(b.references, addr)
+	}
+	sort.Strings(b.references)
+}
+
+// waitedResource returns the resource of the innermost blocking frame, like "mutex 0xc000012345"
+func waitedResource(frames []api.Stackframe) string {
+	for _, frame := range frames {
+		if frame.Function == nil {
+			continue
+		}
+		blocking, ok := blockingFrames[frame.Function.Name()]
+		if !ok {
+			continue
+		}
+		for i := range frame.Arguments {
+			arg := &frame.Arguments[i]
+			if arg.Name != blocking.arg {
+				continue
+			}
+			if addr := pointerTarget(arg); addr != 0 {
+				return fmt.Sprintf("%s %#x", blocking.kind, addr)
+			}
+		}
+	}
+	return ""
+}
+
+// pointerTarget returns the address a pointer variable points to
+func pointerTarget(v *api.Variable) uint64 {
+	if v.Kind != reflect.Ptr || len(v.Children) == 0 {
+		return 0
+	}
+	return v.Children[0].Addr
+}
+
+// collectAddresses records every address reachable from a variable, so that a
+// mutex field of a struct the goroutine works on can be matched
+func collectAddresses(v *api.Variable, seen map[string]bool) {
+	if v.Addr != 0 {
+		seen[fmt.Sprintf("%#x", v.Addr)] = true
+	}
+	if v.Kind == reflect.Chan && v.Base != 0 {
+		seen[fmt.Sprintf("%#x", v.Base)] = true
+	}
+	for i := range v.Children {
+		collectAddresses(&v.Children[i], seen)
+	}
+}
+
+// isUserFunction reports whether a function belongs to the program rather than
+// the runtime or the packages goroutines block in
+func isUserFunction(name string) bool {
+	for _, prefix := range []string{"runtime.", "sync.", "internal/", "syscall."} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// groupBlocked groups blocked goroutines by wait reason and location, largest group first
+func groupBlocked(blocked []*blockedGoroutine) []types.BlockedGroup {
+	index := make(map[string]int)
+	var groups []types.BlockedGroup
+	for _, b := range blocked {
+		key := b.goroutine.WaitReason + "\x00" + b.goroutine.Location
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, types.BlockedGroup{
+				WaitReason: b.goroutine.WaitReason,
+				Location:   b.goroutine.Location,
+			})
+		}
+		groups[i].Count++
+		if len(groups[i].GoroutineIDs) < 20 {
+			groups[i].GoroutineIDs = append(groups[i].GoroutineIDs, b.goroutine.ID)
+		}
+		if b.resource != "" && !containsString(groups[i].Resources, b.resource) && len(groups[i].Resources) < 20 {
+			groups[i].Resources = append(groups[i].Resources, b.resource)
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Count > groups[j].Count })
+	return groups
+}
+
+// findWaitCycles looks for goroutines waiting on each other: A waits on a
+// resource that B's frames refer to while B waits on one A's frames refer to.
+// A reference does not prove ownership, so cycles are likely, not certain, deadlocks.
+func findWaitCycles(blocked []*blockedGoroutine) []types.DeadlockCycle {
+	// Who refers to each resource something waits on
+	waited := make(map[string]bool)
+	for _, b := range blocked {
+		if b.resource != "" {
+			waited[addressOf(b.resource)] = true
+		}
+	}
+	holders := make(map[string][]*blockedGoroutine)
+	for _, b := range blocked {
+		for _, addr := range b.references {
+			if waited[addr] {
+				holders[addr] = append(holders[addr], b)
+			}
+		}
+	}
+
+	// Edge from each waiter to the other goroutines that may hold its resource
+	edges := make(map[int64][]*blockedGoroutine)
+	for _, b := range blocked {
+		if b.resource == "" {
+			continue
+		}
+		for _, h := range holders[addressOf(b.resource)] {
+			if h.goroutine.ID != b.goroutine.ID && h.resource != b.resource {
+				edges[b.goroutine.ID] = append(edges[b.goroutine.ID], h)
+			}
+		}
+	}
+
+	var cycles []types.DeadlockCycle
+	reported := make(map[string]bool)
+	for _, start := range blocked {
+		path := findCycle(start, start, edges, map[int64]bool{})
+		if path == nil {
+			continue
+		}
+
+		var ids []int64
+		var resources []string
+		for _, b := range path {
+			ids = append(ids, b.goroutine.ID)
+			resources = append(resources, b.resource)
+		}
+		key := cycleKey(ids)
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+
+		var steps []string
+		for i, b := range path {
+			next := path[(i+1)%len(path)]
+			steps = append(steps, fmt.Sprintf("goroutine %d waits on %s, which goroutine %d refers to", b.goroutine.ID, b.resource, next.goroutine.ID))
+		}
+		cycles = append(cycles, types.DeadlockCycle{
+			Goroutines:  ids,
+			Resources:   resources,
+			Description: strings.Join(steps, "; "),
+		})
+	}
+	return cycles
+}
+
+// findCycle returns a path from current back to start along edges, if any
+func findCycle(start, current *blockedGoroutine, edges map[int64][]*blockedGoroutine, visited map[int64]bool) []*blockedGoroutine {
+	visited[current.goroutine.ID] = true
+	for _, next := range edges[current.goroutine.ID] {
+		if next.goroutine.ID == start.goroutine.ID {
+			return []*blockedGoroutine{current}
+		}
+		if visited[next.goroutine.ID] {
+			continue
+		}
+		if rest := findCycle(start, next, edges, visited); rest != nil {
+			return append([]*blockedGoroutine{current}, rest...)
+		}
+	}
+	return nil
+}
+
+// cycleKey identifies a cycle regardless of where it was entered
+func cycleKey(ids []int64) string {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return fmt.Sprint(sorted)
+}
+
+// addressOf returns the address part of a resource like "mutex 0xc000012345"
+func addressOf(resource string) string {
+	if i := strings.LastIndex(resource, " "); i >= 0 {
+		return resource[i+1:]
+	}
+	return resource
+}
+
+// deadlockSuspects picks the goroutines most likely involved in a deadlock:
+// cycle members first, then when every goroutine is blocked, the longest blocked
+func deadlockSuspects(blocked []*blockedGoroutine, analysis *types.GoroutineAnalysis) []types.BlockedGoroutine {
+	byID := make(map[int64]*blockedGoroutine, len(blocked))
+	for _, b := range blocked {
+		byID[b.goroutine.ID] = b
+	}
+
+	var suspects []types.BlockedGoroutine
+	added := make(map[int64]bool)
+	add := func(b *blockedGoroutine, blockedFor time.Duration) {
+		if b == nil || added[b.goroutine.ID] || len(suspects) == maxSuspects {
+			return
+		}
+		added[b.goroutine.ID] = true
+		info := blockedInfo(b, blockedFor)
+		info.Stack = b.stack
+		suspects = append(suspects, info)
+	}
+
+	for _, cycle := range analysis.Deadlocks {
+		for _, id := range cycle.Goroutines {
+			add(byID[id], 0)
+		}
+	}
+	for _, long := range analysis.LongBlocked {
+		if analysis.AllBlocked || long.Resource != "" {
+			add(byID[long.ID], time.Duration(long.BlockedNanos))
+		}
+	}
+	if analysis.AllBlocked {
+		for _, b := range blocked {
+			add(b, 0)
+		}
+	}
+	return suspects
+}
+
+// blockedInfo describes a blocked goroutine for responses
+func blockedInfo(b *blockedGoroutine, blockedFor time.Duration) types.BlockedGoroutine {
+	info := types.BlockedGoroutine{
+		Goroutine: b.goroutine,
+		Resource:  b.resource,
+	}
+	if blockedFor > 0 {
+		info.BlockedFor = blockedFor.Round(time.Millisecond).String()
+		info.BlockedNanos = int64(blockedFor)
+	}
+	return info
+}
+
+// runtimeNow estimates the target's monotonic clock, which WaitSince is
+// measured in. The runtime records WaitSince at the next GC, so the start of
+// the latest GC cycle is the best reference available from a stopped process.
+func (c *Client) runtimeNow(blocked []*blockedGoroutine) int64 {
+	var now int64
+	for _, b := range blocked {
+		if b.waitSince > now {
+			now = b.waitSince
+		}
+	}
+
+	state, err := c.client.GetState()
+	if err != nil || state.SelectedGoroutine == nil {
+		return now
+	}
+	scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID}
+	v, err := c.client.EvalVariable(scope, "runtime.work.tstart", api.LoadConfig{})
+	if err != nil {
+		logger.Debug("Warning: Failed to read runtime.work.tstart: %v", err)
+		return now
+	}
+	var tstart int64
+	if _, err := fmt.Sscan(v.Value, &tstart); err == nil && tstart > now {
+		now = tstart
+	}
+	return now
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// createAnalyzeGoroutinesResponse creates a response for the analyze goroutines command
+func (c *Client) createAnalyzeGoroutinesResponse(state *api.DebuggerState, analysis *types.GoroutineAnalysis, err error) types.AnalyzeGoroutinesResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "analyze_goroutines"
+
+	response := types.AnalyzeGoroutinesResponse{
+		Status:   "success",
+		Context:  context,
+		Analysis: analysis,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("%d of %d goroutines blocked in %d groups", analysis.Blocked, analysis.Total, len(analysis.Groups)))
+	if len(analysis.Groups) > 0 {
+		top := analysis.Groups[0]
+		summary.WriteString(fmt.Sprintf("; largest: %d on %s at %s", top.Count, top.WaitReason, top.Location))
+	}
+	if len(analysis.Deadlocks) > 0 {
+		summary.WriteString(fmt.Sprintf("; %d likely deadlock cycles", len(analysis.Deadlocks)))
+	}
+	if analysis.AllBlocked {
+		summary.WriteString("; every goroutine is blocked, the program cannot make progress")
+	}
+	if len(analysis.LongBlocked) > 0 {
+		summary.WriteString(fmt.Sprintf("; %d blocked for at least %s", len(analysis.LongBlocked), analysis.LongBlocked[len(analysis.LongBlocked)-1].BlockedFor))
+	}
+	response.Summary = summary.String()
+	return response
+}
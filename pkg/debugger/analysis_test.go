@@ -0,0 +1,56 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestWaitedResource(t *testing.T) {
+	mutex := api.Variable{Name: "m", Kind: reflect.Ptr, Children: []api.Variable{{Addr: 0xc000012340}}}
+	frames := []api.Stackframe{
+		{Location: api.Location{Function: &api.Function{Name_: "runtime.gopark"}}},
+		{Location: api.Location{Function: &api.Function{Name_: "sync.(*Mutex).lockSlow"}}, Arguments: []api.Variable{mutex}},
+		{Location: api.Location{Function: &api.Function{Name_: "main.transfer"}}},
+	}
+	if got := waitedResource(frames); got != "mutex 0xc000012340" {
+		t.Errorf("Expected the mutex address, got %q", got)
+	}
+}
+
+func TestFindWaitCycles(t *testing.T) {
+	blocked := func(id int64, reason, location, resource string, refs ...string) *blockedGoroutine {
+		return &blockedGoroutine{
+			goroutine:  types.Goroutine{ID: id, WaitReason: reason, Location: location},
+			resource:   resource,
+			references: refs,
+		}
+	}
+
+	// transfer(a, b) and transfer(b, a) each hold one account's lock and wait on the other
+	g1 := blocked(1, "sync.Mutex.Lock", "main.transfer at /src/bank.go:12", "mutex 0xb0", "0xa0", "0xb0")
+	g2 := blocked(2, "sync.Mutex.Lock", "main.transfer at /src/bank.go:12", "mutex 0xa0", "0xa0", "0xb0")
+	g3 := blocked(3, "chan receive", "main.worker at /src/worker.go:8", "chan 0xc0", "0xc0")
+
+	cycles := findWaitCycles([]*blockedGoroutine{g1, g2, g3})
+	if len(cycles) != 1 {
+		t.Fatalf("Expected one cycle, got %+v", cycles)
+	}
+	if !reflect.DeepEqual(cycles[0].Goroutines, []int64{1, 2}) ||
+		!reflect.DeepEqual(cycles[0].Resources, []string{"mutex 0xb0", "mutex 0xa0"}) {
+		t.Errorf("Unexpected cycle %+v", cycles[0])
+	}
+
+	groups := groupBlocked([]*blockedGoroutine{g3, g1, g2})
+	if len(groups) != 2 || groups[0].Count != 2 || !reflect.DeepEqual(groups[0].GoroutineIDs, []int64{1, 2}) {
+		t.Errorf("Expected the two transfers grouped first, got %+v", groups)
+	}
+
+	analysis := &types.GoroutineAnalysis{Deadlocks: cycles}
+	suspects := deadlockSuspects([]*blockedGoroutine{g1, g2, g3}, analysis)
+	if len(suspects) != 2 || suspects[0].ID != 1 || suspects[1].Resource != "mutex 0xa0" {
+		t.Errorf("Expected the cycle members as suspects, got %+v", suspects)
+	}
+}
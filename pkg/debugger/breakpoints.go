@@ -20,6 +20,15 @@ func (c *Client) SetBreakpoint(file string, line int) types.BreakpointResponse {
 			},
 		}
 	}
+	if err := c.readOnlyError("set_breakpoint"); err != nil {
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				ErrorMessage: err.Error(),
+				Timestamp:    getCurrentTimestamp(),
+			},
+		}
+	}
 
 	logger.Debug("Setting breakpoint at %s:%d", file, line)
 	bp, err := c.client.CreateBreakpoint(&api.Breakpoint{
@@ -122,6 +131,15 @@ func (c *Client) RemoveBreakpoint(id int) types.BreakpointResponse {
 			},
 		}
 	}
+	if err := c.readOnlyError("remove_breakpoint"); err != nil {
+		return types.BreakpointResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				ErrorMessage: err.Error(),
+				Timestamp:    getCurrentTimestamp(),
+			},
+		}
+	}
 
 	// Get breakpoint info before removing
 	bps, err := c.client.ListBreakpoints(false)
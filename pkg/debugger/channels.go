@@ -0,0 +1,219 @@
+package debugger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxChannelWaiters bounds how many parked goroutines are listed per queue
+const maxChannelWaiters = 50
+
+// maxChannelBuffered bounds how many buffered values are read per channel
+const maxChannelBuffered = 100
+
+// inspectChannel reads a channel's runtime.hchan: its buffer, closed state
+// and the goroutines parked sending to or receiving from it
+func (c *Client) inspectChannel(scope api.EvalScope, v *api.Variable, cfg api.LoadConfig) (*types.ChannelInfo, error) {
+	info := &types.ChannelInfo{ElemType: channelElemType(v.Type)}
+	if v.Base == 0 {
+		info.Nil = true
+		return info, nil
+	}
+	info.Address = fmt.Sprintf("%#x", v.Base)
+
+	hchan := fmt.Sprintf("(*runtime.hchan)(%#x)", v.Base)
+	fields := make(map[string]uint64)
+	for _, field := range []string{"qcount", "dataqsiz", "closed", "recvx"} {
+		value, err := c.evalUint(scope, hchan+"."+field)
+		if err != nil {
+			return nil, err
+		}
+		fields[field] = value
+	}
+	info.Len = int(fields["qcount"])
+	info.Capacity = int(fields["dataqsiz"])
+	info.Closed = fields["closed"] != 0
+
+	if info.Len > 0 {
+		buffered, err := c.channelBuffer(scope, hchan, info, int(fields["recvx"]), cfg)
+		if err != nil {
+			// The channel is still worth describing without its values
+			logger.Debug("Warning: Failed to read buffer of channel %s: %v", info.Address, err)
+		}
+		info.Buffered = buffered
+	}
+
+	var err error
+	if info.SendWaiters, err = c.channelWaiters(scope, hchan+".sendq.first"); err != nil {
+		return nil, err
+	}
+	if info.RecvWaiters, err = c.channelWaiters(scope, hchan+".recvq.first"); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// channelBuffer reads the buffered values in the order they will be received,
+// loading only the occupied slots of the buffer
+func (c *Client) channelBuffer(scope api.EvalScope, hchan string, info *types.ChannelInfo, recvx int, cfg api.LoadConfig) ([]string, error) {
+	buf, err := c.evalUint(scope, "uintptr("+hchan+".buf)")
+	if err != nil {
+		return nil, err
+	}
+	elemSize, err := c.evalUint(scope, hchan+".elemsize")
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	for _, slots := range bufferSlots(recvx, info.Len, info.Capacity) {
+		cfg.MaxArrayValues = slots.count
+		expr := fmt.Sprintf("*(*[%d]%s)(%#x)", slots.count, delveTypeName(info.ElemType), buf+uint64(slots.start)*elemSize)
+		v, err := c.client.EvalVariable(scope, expr, cfg)
+		if err != nil {
+			return values, err
+		}
+		for i := range v.Children {
+			values = append(values, convertVariable(&v.Children[i], "").Value)
+		}
+	}
+	return values, nil
+}
+
+// channelWaiters walks a wait queue of runtime.sudog starting at the pointer
+// expression first, reporting each parked goroutine and where it is in user code
+func (c *Client) channelWaiters(scope api.EvalScope, first string) ([]types.ChannelWaiter, error) {
+	var waiters []types.ChannelWaiter
+	expr := first
+	for len(waiters) < maxChannelWaiters {
+		ptr, err := c.client.EvalVariable(scope, expr, api.LoadConfig{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read wait queue: %v", err)
+		}
+		sudog := pointerTarget(ptr)
+		if sudog == 0 {
+			break
+		}
+
+		goid, err := c.evalUint(scope, fmt.Sprintf("(*runtime.sudog)(%#x).g.goid", sudog))
+		if err != nil {
+			return nil, err
+		}
+		waiter := types.ChannelWaiter{GoroutineID: int64(goid)}
+		frames, err := c.client.Stacktrace(waiter.GoroutineID, 20, 0, nil)
+		if err != nil {
+			logger.Debug("Warning: Failed to get stack of goroutine %d: %v", goid, err)
+		}
+		for _, frame := range frames {
+			if frame.Function != nil && isUserFunction(frame.Function.Name()) {
+				waiter.Location = formatFrame(frame)
+				break
+			}
+		}
+		waiters = append(waiters, waiter)
+
+		expr = fmt.Sprintf("(*runtime.sudog)(%#x).next", sudog)
+	}
+	return waiters, nil
+}
+
+// evalUint evaluates an expression with an unsigned integer value
+func (c *Client) evalUint(scope api.EvalScope, expr string) (uint64, error) {
+	v, err := c.client.EvalVariable(scope, expr, api.LoadConfig{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to evaluate %s: %v", expr, err)
+	}
+	value, err := strconv.ParseUint(v.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected value %q for %s", v.Value, expr)
+	}
+	return value, nil
+}
+
+// slotRange is a run of consecutive buffer slots
+type slotRange struct {
+	start int
+	count int
+}
+
+// bufferSlots returns the buffer slots holding values, oldest first and at
+// most maxChannelBuffered. The buffer is a ring whose next value to receive is
+// at recvx, so the slots are one run or, when they wrap around, two.
+func bufferSlots(recvx, count, capacity int) []slotRange {
+	if capacity == 0 || count == 0 {
+		return nil
+	}
+	count = min(count, maxChannelBuffered, capacity)
+	first := slotRange{start: recvx % capacity, count: min(count, capacity-recvx%capacity)}
+	if first.count == count {
+		return []slotRange{first}
+	}
+	return []slotRange{first, {start: 0, count: count - first.count}}
+}
+
+// channelElemType returns the element type of a channel type such as "chan<- int"
+func channelElemType(chanType string) string {
+	for _, prefix := range []string{"chan<- ", "<-chan ", "chan "} {
+		if strings.HasPrefix(chanType, prefix) {
+			return strings.TrimPrefix(chanType, prefix)
+		}
+	}
+	return chanType
+}
+
+// delveTypeName quotes the package path of a type name, which Delve
+// expressions require when it contains a slash: "example.com/pkg".T
+func delveTypeName(name string) string {
+	slash := strings.LastIndex(name, "/")
+	if slash < 0 {
+		return name
+	}
+	dot := strings.Index(name[slash:], ".")
+	if dot < 0 {
+		return name
+	}
+	start := strings.LastIndexAny(name[:slash], "*[]) ") + 1
+	end := slash + dot
+	return name[:start] + strconv.Quote(name[start:end]) + name[end:]
+}
+
+// formatChannel formats a channel like "chan int (len 2, cap 4) [1, 2]; 1 receiver waiting"
+func formatChannel(chanType string, info *types.ChannelInfo) string {
+	if info.Nil {
+		return chanType + " nil"
+	}
+
+	var state []string
+	if info.Capacity == 0 {
+		state = append(state, "unbuffered")
+	} else {
+		state = append(state, fmt.Sprintf("len %d, cap %d", info.Len, info.Capacity))
+	}
+	if info.Closed {
+		state = append(state, "closed")
+	}
+	value := fmt.Sprintf("%s (%s)", chanType, strings.Join(state, ", "))
+	if len(info.Buffered) > 0 {
+		value += " [" + strings.Join(info.Buffered, ", ") + "]"
+	}
+	if n := len(info.SendWaiters); n > 0 {
+		value += fmt.Sprintf("; %d %s waiting", n, plural(n, "sender", "senders"))
+	}
+	if n := len(info.RecvWaiters); n > 0 {
+		value += fmt.Sprintf("; %d %s waiting", n, plural(n, "receiver", "receivers"))
+	}
+	return value
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,59 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestBufferSlots(t *testing.T) {
+	// A ring of 4 whose oldest value is in slot 3
+	if got := bufferSlots(3, 3, 4); !reflect.DeepEqual(got, []slotRange{{3, 1}, {0, 2}}) {
+		t.Errorf("Expected slots 3, then 0 and 1, got %v", got)
+	}
+	// Only the occupied slots of a large buffer
+	if got := bufferSlots(10, 2, 1000); !reflect.DeepEqual(got, []slotRange{{10, 2}}) {
+		t.Errorf("Expected slots 10 and 11, got %v", got)
+	}
+	if got := bufferSlots(990, 500, 1000); !reflect.DeepEqual(got, []slotRange{{990, 10}, {0, maxChannelBuffered - 10}}) {
+		t.Errorf("Expected %d slots from 990, got %v", maxChannelBuffered, got)
+	}
+	if got := bufferSlots(0, 0, 0); got != nil {
+		t.Errorf("Expected no slots for an unbuffered channel, got %v", got)
+	}
+}
+
+func TestDelveTypeName(t *testing.T) {
+	tests := map[string]string{
+		"int":                          "int",
+		"main.Job":                     "main.Job",
+		"*github.com/acme/queue.Job":   `*"github.com/acme/queue".Job`,
+		"[]github.com/acme/queue.Job":  `[]"github.com/acme/queue".Job`,
+		"github.com/acme/queue.Result": `"github.com/acme/queue".Result`,
+	}
+	for name, want := range tests {
+		if got := delveTypeName(name); got != want {
+			t.Errorf("delveTypeName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFormatChannel(t *testing.T) {
+	info := &types.ChannelInfo{
+		ElemType:    "int",
+		Capacity:    4,
+		Len:         2,
+		Closed:      true,
+		Buffered:    []string{"1", "2"},
+		RecvWaiters: []types.ChannelWaiter{{GoroutineID: 7}},
+	}
+	want := "chan int (len 2, cap 4, closed) [1, 2]; 1 receiver waiting"
+	if got := formatChannel("chan int", info); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if got := channelElemType("<-chan main.Job"); got != "main.Job" {
+		t.Errorf("Expected main.Job, got %q", got)
+	}
+}
@@ -7,12 +7,17 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/rpc2"
 	"github.com/go-delve/delve/service/rpccommon"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
@@ -20,19 +25,50 @@ import (
 type Client struct {
 	client      *rpc2.RPCClient
 	target      string
+	builtBinary string      // Debug binary built for this session, removed on Close
+	started     *os.Process // Program started with its own environment, which Delve only attached to
 	pid         int
 	server      *rpccommon.ServerImpl
 	tempDir     string
-	stdout      bytes.Buffer       // Buffer for captured stdout
-	stderr      bytes.Buffer       // Buffer for captured stderr
+	output      *outputBuffer      // Captured stdout and stderr lines, bounded
+	readCursor  int                // Line number following the last get_debugger_output read
 	outputChan  chan OutputMessage // Channel for captured output
 	stopOutput  chan struct{}      // Channel to signal stopping output capture
 	outputMutex sync.Mutex         // Mutex for synchronizing output buffer access
+	startTime   time.Time          // When the debug session was established
+
+	failureBreakpoints map[int]string // Breakpoint ID -> testing method, with stopOnFailure
+
+	outputDone    sync.WaitGroup      // Output capture goroutines still reading
+	outputHandler func(OutputMessage) // Receives output lines as they are captured
+	droppedOutput int                 // Lines not forwarded because the handler fell behind
+	exited        bool                // The debuggee has exited; its output stays readable until Close
+	exitStatus    int                 // Exit status reported by Delve once exited
+	lastLocation  *string             // Last location the debuggee stopped at
+
+	outputBreaks      []*outputBreak // Patterns halting the program when printed, guarded by outputMutex
+	nextOutputBreakID int
+	pendingOutputHit  *outputHit  // Matched line not yet reported, guarded by outputMutex
+	continuing        atomic.Bool // A continue is in progress and can be halted
+
+	goroutineSnapshots map[string]*goroutineSnapshot // Named goroutine sets for diffing
+	waitReasonNames    []string                      // The target's runtime.waitReasonStrings, once read
+
+	coreFile   string // Core dump being inspected; the session is read-only
+	remoteAddr string // Address of a Delve server joined with connect
+
+	stdin       *os.File    // Write end of the stdin pipe, with stdinText or stdinPipe
+	stdinMu     sync.Mutex  // Serializes stdin writes
+	stdinClosed atomic.Bool // Stdin was closed to signal EOF
 }
 
+// maxRecentOutput is the number of output lines reported when the debuggee exits
+const maxRecentOutput = 10
+
 // NewClient creates a new Delve client wrapper
 func NewClient() *Client {
 	return &Client{
+		output:     newOutputBuffer(maxOutputLines, maxOutputBytes),
 		outputChan: make(chan OutputMessage, 100), // Buffer for output messages
 		stopOutput: make(chan struct{}),
 	}
@@ -48,6 +84,70 @@ func (c *Client) GetPid() int {
 	return c.pid
 }
 
+// GetStartTime returns when the debug session was established
+func (c *Client) GetStartTime() time.Time {
+	return c.startTime
+}
+
+// IsActive reports whether the client is connected to a debug server
+func (c *Client) IsActive() bool {
+	return c.client != nil
+}
+
+// SessionInfo describes the debug session for listing purposes
+func (c *Client) SessionInfo(id string) types.SessionInfo {
+	info := types.SessionInfo{
+		ID:        id,
+		Target:    c.target,
+		Pid:       c.pid,
+		State:     "inactive",
+		StartedAt: c.startTime,
+	}
+
+	if !c.startTime.IsZero() {
+		info.Uptime = time.Since(c.startTime).Round(time.Second).String()
+	}
+
+	if c.client == nil {
+		return info
+	}
+
+	if c.exited {
+		info.State = "exited"
+		info.ExitCode = c.exitCode()
+		info.CurrentLocation = c.lastLocation
+		return info
+	}
+
+	if c.coreFile != "" {
+		info.Target = c.coreFile
+		info.State = "core"
+	}
+	if c.IsRemote() {
+		info.Target = c.remoteAddr
+	}
+
+	// Use the non-blocking variant so a running target doesn't stall the listing
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		info.State = "unknown"
+		return info
+	}
+
+	switch {
+	case c.coreFile != "":
+	case state.Exited:
+		info.State = "exited"
+	case state.Running:
+		info.State = "running"
+	default:
+		info.State = "stopped"
+	}
+	info.CurrentLocation = getCurrentLocation(state)
+
+	return info
+}
+
 // Helper function to get an available port
 func getFreePort() (int, error) {
 	addr, err := net.ResolveTCPAddr("tcp", "localhost:0")
@@ -100,32 +200,144 @@ func waitForStop(c *Client, timeout time.Duration) (*api.DebuggerState, error) {
 
 // captureOutput reads from a reader and sends the output to the output channel and buffer
 func (c *Client) captureOutput(reader io.ReadCloser, source string) {
+	defer c.outputDone.Done()
 	defer reader.Close()
 
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		// Write to appropriate buffer
-		if source == "stdout" {
-			c.stdout.WriteString(line + "\n")
-		} else if source == "stderr" {
-			c.stderr.WriteString(line + "\n")
+	lines := bufio.NewReader(reader)
+	for {
+		line, err := readOutputLine(lines)
+		if err != nil {
+			return
 		}
 
-		// Also send to channel for real-time monitoring
+		// Write to the buffer, which numbers the line
+		c.outputMutex.Lock()
+		msg := c.output.append(source, line, time.Now())
+		c.matchOutputBreaks(msg)
+		c.outputMutex.Unlock()
+
+		// Also send to channel for real-time monitoring. Never block here: a
+		// stalled reader would stall the program, and the line is already buffered.
 		select {
 		case <-c.stopOutput:
 			return
-		case c.outputChan <- OutputMessage{
-			Source:    source,
-			Content:   line,
-			Timestamp: time.Now(),
-		}:
+		default:
+		}
+		select {
+		case c.outputChan <- msg:
+		default:
+			c.outputMutex.Lock()
+			c.droppedOutput++
+			c.outputMutex.Unlock()
 		}
 	}
 }
 
+// stopCapture ends the output capture of a launch that failed. Closing a
+// redirect's write end gives its reader EOF, but on Unix the reader may still
+// wait for a writer to open the FIFO, so the FIFOs are opened briefly until
+// the capture goroutines are done.
+func (c *Client) stopCapture(redirects ...proc.OutputRedirect) {
+	close(c.stopOutput)
+
+	done := make(chan struct{})
+	go func() {
+		c.outputDone.Wait()
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for {
+		for _, redirect := range redirects {
+			if redirect.File != nil {
+				redirect.File.Close()
+			}
+			if redirect.Path != "" {
+				if f, err := os.OpenFile(redirect.Path, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+					f.Close()
+				}
+			}
+		}
+
+		select {
+		case <-done:
+			return
+		case <-deadline:
+			logger.Debug("Warning: Output capture did not stop after the failed launch")
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+// maxOutputLineLength bounds a captured line; the rest of a longer line is
+// read and dropped so the program never blocks writing it
+const maxOutputLineLength = 64 * 1024
+
+// readOutputLine reads the next line without its line ending, truncated to
+// maxOutputLineLength bytes. A final line without a newline is returned too.
+func readOutputLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	truncated := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		chunk = bytes.TrimSuffix(chunk, []byte("\n"))
+		if room := maxOutputLineLength - len(line); len(chunk) > room {
+			chunk = chunk[:room]
+			truncated = true
+		}
+		line = append(line, chunk...)
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && len(line) == 0 && !truncated {
+			return "", err
+		}
+
+		text := string(bytes.TrimSuffix(line, []byte("\r")))
+		if truncated {
+			text += " [line truncated]"
+		}
+		return text, nil
+	}
+}
+
+// recordExit marks the debuggee as exited. The last output lines are usually
+// still in flight when Delve reports the exit, so give the readers a moment to drain.
+func (c *Client) recordExit(status int) {
+	if c.exited {
+		return
+	}
+	c.exited = true
+	c.exitStatus = status
+	logger.Debug("Process exited with status %d", status)
+
+	drained := make(chan struct{})
+	go func() {
+		c.outputDone.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+// exitCode returns the exit status of the debuggee, or nil while it is alive
+func (c *Client) exitCode() *int {
+	if !c.exited {
+		return nil
+	}
+	status := c.exitStatus
+	return &status
+}
+
+// exitedError reports that the debuggee can no longer be controlled
+func (c *Client) exitedError() error {
+	return fmt.Errorf("process has exited with status %d", c.exitStatus)
+}
+
 // createDebugContext creates a debug context from a state
 func (c *Client) createDebugContext(state *api.DebuggerState) types.DebugContext {
 	context := types.DebugContext{
@@ -148,7 +360,31 @@ func (c *Client) createDebugContext(state *api.DebuggerState) types.DebugContext
 		// Get local variables if we have a client
 		if c != nil {
 			context.LocalVariables, _ = c.getLocalVariables(state)
+
+			if state.Exited {
+				c.recordExit(state.ExitStatus)
+			} else if context.CurrentLocation != nil {
+				c.lastLocation = context.CurrentLocation
+			}
+		}
+	}
+
+	// Exit is a terminal state: every later response reports it with what led up to it
+	if c != nil && c.exited {
+		context.Exited = true
+		context.ExitCode = c.exitCode()
+		context.LastLocation = c.lastLocation
+		context.StopReason = fmt.Sprintf("process exited with status %d", c.exitStatus)
+
+		c.outputMutex.Lock()
+		for _, line := range c.output.tail(maxRecentOutput) {
+			if line.Source == "stderr" {
+				context.LastOutput = append(context.LastOutput, "[stderr] "+line.Content)
+			} else {
+				context.LastOutput = append(context.LastOutput, line.Content)
+			}
 		}
+		c.outputMutex.Unlock()
 	}
 
 	return context
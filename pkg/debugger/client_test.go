@@ -0,0 +1,189 @@
+package debugger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestCreateDebugContextAfterExit(t *testing.T) {
+	c := NewClient()
+	c.output.append("stdout", "starting", time.Now())
+	c.output.append("stderr", "panic: boom", time.Now())
+
+	stopped := &api.DebuggerState{
+		CurrentThread: &api.Thread{File: "/src/main.go", Line: 12, Function: &api.Function{Name_: "main.main"}},
+	}
+	context := c.createDebugContext(stopped)
+	if context.Exited || context.ExitCode != nil {
+		t.Fatalf("Expected a running program, got exited=%v", context.Exited)
+	}
+
+	context = c.createDebugContext(&api.DebuggerState{Exited: true, ExitStatus: 2})
+	if !context.Exited || context.ExitCode == nil || *context.ExitCode != 2 {
+		t.Fatalf("Expected exit status 2, got exited=%v exitCode=%v", context.Exited, context.ExitCode)
+	}
+	if context.LastLocation == nil || *context.LastLocation != "At /src/main.go:12 in main.main" {
+		t.Errorf("Expected the last stop location, got %v", context.LastLocation)
+	}
+	if len(context.LastOutput) != 2 || context.LastOutput[1] != "[stderr] panic: boom" {
+		t.Errorf("Expected the last output lines, got %v", context.LastOutput)
+	}
+
+	// Later responses keep reporting the exit without a Delve state
+	context = c.createDebugContext(nil)
+	if !context.Exited || context.StopReason != "process exited with status 2" {
+		t.Errorf("Expected the exit to be reported after the fact, got %+v", context)
+	}
+	if err := c.exitedError(); err == nil || err.Error() != "process has exited with status 2" {
+		t.Errorf("Expected an exited error, got %v", err)
+	}
+}
+
+func TestCaptureOutputNeverBlocks(t *testing.T) {
+	c := NewClient()
+	reader, writer := io.Pipe()
+
+	// Nothing drains the output channel, as when no handler keeps up
+	c.outputDone.Add(1)
+	go c.captureOutput(reader, "stdout")
+
+	const lines = 500
+	written := make(chan struct{})
+	go func() {
+		for i := 0; i < lines; i++ {
+			fmt.Fprintf(writer, "line %d\n", i)
+		}
+		writer.Close()
+		close(written)
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Program output blocked behind the output channel")
+	}
+	c.outputDone.Wait()
+
+	if got := len(c.output.since(0)); got != lines {
+		t.Errorf("Expected %d buffered lines, got %d", lines, got)
+	}
+	if c.droppedOutput != lines-cap(c.outputChan) {
+		t.Errorf("Expected %d unstreamed lines, got %d", lines-cap(c.outputChan), c.droppedOutput)
+	}
+
+	// The forwarder delivers what was queued, in order
+	received := make(chan OutputMessage, lines)
+	c.SetOutputHandler(func(msg OutputMessage) { received <- msg })
+	go c.forwardOutput()
+	defer close(c.stopOutput)
+
+	for i := 0; i < cap(c.outputChan); i++ {
+		select {
+		case msg := <-received:
+			if expected := fmt.Sprintf("line %d", i); msg.Content != expected || msg.Source != "stdout" {
+				t.Fatalf("Expected stdout %q, got %s %q", expected, msg.Source, msg.Content)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for line %d", i)
+		}
+	}
+}
+
+func TestCaptureOutputTruncatesLongLines(t *testing.T) {
+	c := NewClient()
+	reader, writer := io.Pipe()
+
+	c.outputDone.Add(1)
+	go c.captureOutput(reader, "stdout")
+
+	written := make(chan struct{})
+	go func() {
+		fmt.Fprintf(writer, "%s\r\n", strings.Repeat("x", 3*maxOutputLineLength))
+		fmt.Fprint(writer, "after\nunterminated")
+		writer.Close()
+		close(written)
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Program output blocked on a long line")
+	}
+	c.outputDone.Wait()
+
+	lines := c.output.since(0)
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(lines))
+	}
+	if expected := strings.Repeat("x", maxOutputLineLength) + " [line truncated]"; lines[0].Content != expected {
+		t.Errorf("Expected the long line truncated, got %d bytes", len(lines[0].Content))
+	}
+	if lines[1].Content != "after" || lines[2].Content != "unterminated" {
+		t.Errorf("Expected the following lines intact, got %q and %q", lines[1].Content, lines[2].Content)
+	}
+}
+
+func TestFailedLaunchStopsCapture(t *testing.T) {
+	program := filepath.Join(t.TempDir(), "program")
+	if err := os.WriteFile(program, []byte("not a program"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient()
+	response := c.LaunchProgram(program, nil, types.LaunchOptions{Env: map[string]string{"MCP_FAILED": "1"}})
+	if response.Context.ErrorMessage == "" {
+		t.Fatal("Expected the launch to fail")
+	}
+
+	select {
+	case <-c.stopOutput:
+	default:
+		t.Error("Expected output forwarding to be stopped")
+	}
+	captured := make(chan struct{})
+	go func() {
+		c.outputDone.Wait()
+		close(captured)
+	}()
+	select {
+	case <-captured:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected output capture to end with the failed launch")
+	}
+}
+
+func TestCloseWithoutKillKeepsLaunchedSession(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "__debug_bin")
+	if err := os.WriteFile(binary, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient()
+	// Refusing must not talk to Delve, so the client is never connected
+	c.client = &rpc2.RPCClient{}
+	c.target = binary
+
+	if _, err := c.Close(false); err == nil || !strings.Contains(err.Error(), "kill") {
+		t.Fatalf("Expected detaching a launched program to fail, got %v", err)
+	}
+	if !c.IsActive() {
+		t.Errorf("Expected the session to stay open")
+	}
+	select {
+	case <-c.stopOutput:
+		t.Errorf("Expected output capture to keep draining the program's pipes")
+	default:
+	}
+	if _, err := os.Stat(binary); err != nil {
+		t.Errorf("Expected the binary to be kept: %v", err)
+	}
+}
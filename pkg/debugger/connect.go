@@ -0,0 +1,77 @@
+package debugger
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// connectTimeout bounds how long dialing a headless Delve server may take
+const connectTimeout = 5 * time.Second
+
+// Connect joins a Delve server that is already running, such as one started
+// with dlv --headless --listen=:2345. The server and its target are not owned
+// by this session: closing it detaches unless asked to kill the target.
+func (c *Client) Connect(addr string) types.ConnectResponse {
+	if c.client != nil {
+		return c.createConnectResponse(nil, addr, fmt.Errorf("debug session already active"))
+	}
+
+	logger.Debug("Connecting to Delve server at %s", addr)
+
+	// rpc2.NewClient exits the process when dialing fails, so dial here first
+	conn, err := net.DialTimeout("tcp", addr, connectTimeout)
+	if err != nil {
+		return c.createConnectResponse(nil, addr, fmt.Errorf("failed to connect to Delve server at %s: %v", addr, err))
+	}
+
+	// Something that accepts connections but never answers must not hang the handshake
+	conn.SetDeadline(time.Now().Add(connectTimeout))
+	client := rpc2.NewClientFromConn(conn)
+
+	// Unlike GetState, this answers at once while the target is running
+	state, err := client.GetStateNonBlocking()
+	if err != nil {
+		client.Disconnect(false)
+		return c.createConnectResponse(nil, addr, fmt.Errorf("%s is not a Delve API v2 server: %v", addr, err))
+	}
+	conn.SetDeadline(time.Time{})
+
+	c.client = client
+	c.remoteAddr = addr
+	c.pid = client.ProcessPid()
+	c.startTime = time.Now()
+
+	return c.createConnectResponse(state, addr, nil)
+}
+
+// IsRemote reports whether the session joined a Delve server it did not start
+func (c *Client) IsRemote() bool {
+	return c.remoteAddr != ""
+}
+
+// createConnectResponse creates a response for the connect command
+func (c *Client) createConnectResponse(state *api.DebuggerState, addr string, err error) types.ConnectResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "connect"
+
+	response := types.ConnectResponse{
+		Status:  "success",
+		Context: &context,
+		Address: addr,
+		Pid:     c.pid,
+	}
+	if err != nil {
+		response.Status = "error"
+		context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.Summary = fmt.Sprintf("Connected to the Delve server at %s debugging PID %d; program output is not captured for remote targets", addr, c.pid)
+	return response
+}
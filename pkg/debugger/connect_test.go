@@ -0,0 +1,48 @@
+package debugger
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestConnectFailures(t *testing.T) {
+	// Nothing listens on a port that was just released
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	response := NewClient().Connect(addr)
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "failed to connect") {
+		t.Errorf("Expected a connection error, got %+v", response.Context)
+	}
+
+	// A server that accepts but speaks something else
+	listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	c := NewClient()
+	response = c.Connect(listener.Addr().String())
+	if response.Status != "error" || !strings.Contains(response.Context.ErrorMessage, "not a Delve API v2 server") {
+		t.Errorf("Expected a protocol error, got %+v", response.Context)
+	}
+	if c.IsActive() || c.IsRemote() {
+		t.Errorf("Expected no session after a failed connect")
+	}
+}
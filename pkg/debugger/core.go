@@ -0,0 +1,142 @@
+package debugger
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-delve/delve/pkg/logflags"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
+	"github.com/go-delve/delve/service/debugger"
+	"github.com/go-delve/delve/service/rpc2"
+	"github.com/go-delve/delve/service/rpccommon"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// OpenCore loads a core dump with the executable that produced it for
+// post-mortem inspection. The session is read-only: nothing can be resumed.
+func (c *Client) OpenCore(coreFile string, executable string) types.OpenCoreResponse {
+	if c.client != nil {
+		return c.createOpenCoreResponse(nil, coreFile, executable, fmt.Errorf("debug session already active"))
+	}
+
+	absCore, err := filepath.Abs(coreFile)
+	if err != nil {
+		return c.createOpenCoreResponse(nil, coreFile, executable, fmt.Errorf("failed to get absolute path: %v", err))
+	}
+	if _, err := os.Stat(absCore); err != nil {
+		return c.createOpenCoreResponse(nil, coreFile, executable, fmt.Errorf("core file not found: %s", absCore))
+	}
+	absExecutable, err := filepath.Abs(executable)
+	if err != nil {
+		return c.createOpenCoreResponse(nil, coreFile, executable, fmt.Errorf("failed to get absolute path: %v", err))
+	}
+	if _, err := os.Stat(absExecutable); err != nil {
+		return c.createOpenCoreResponse(nil, absCore, executable, fmt.Errorf("executable not found: %s", absExecutable))
+	}
+
+	logger.Debug("Opening core %s of %s", absCore, absExecutable)
+
+	// Get an available port for the debug server
+	port, err := getFreePort()
+	if err != nil {
+		return c.createOpenCoreResponse(nil, absCore, absExecutable, fmt.Errorf("failed to find available port: %v", err))
+	}
+
+	// Configure Delve logging
+	logflags.Setup(false, "", "")
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return c.createOpenCoreResponse(nil, absCore, absExecutable, fmt.Errorf("couldn't start listener: %s", err))
+	}
+
+	// The core backend takes the executable as the first process argument
+	config := &service.Config{
+		Listener:    listener,
+		APIVersion:  2,
+		AcceptMulti: true,
+		ProcessArgs: []string{absExecutable},
+		Debugger: debugger.Config{
+			CoreFile:       absCore,
+			Backend:        "default",
+			CheckGoVersion: true,
+		},
+	}
+
+	server := rpccommon.NewServer(config)
+	if server == nil {
+		return c.createOpenCoreResponse(nil, absCore, absExecutable, fmt.Errorf("failed to create debug server"))
+	}
+	c.server = server
+
+	serverReady := make(chan error, 1)
+	go func() {
+		if err := server.Run(); err != nil {
+			logger.Debug("Debug server error: %v", err)
+			serverReady <- err
+		}
+	}()
+
+	// Try to connect to the server with a timeout; large cores take a while to load
+	addr := listener.Addr().String()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return c.createOpenCoreResponse(nil, absCore, absExecutable, fmt.Errorf("timed out waiting for debug server to load the core"))
+		case err := <-serverReady:
+			return c.createOpenCoreResponse(nil, absCore, absExecutable, fmt.Errorf("failed to open core: %v", err))
+		default:
+			client := rpc2.NewClient(addr)
+			state, err := client.GetState()
+			if err == nil && state != nil {
+				c.client = client
+				c.coreFile = absCore
+				c.pid = client.ProcessPid()
+				c.startTime = time.Now()
+				return c.createOpenCoreResponse(state, absCore, absExecutable, nil)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+}
+
+// readOnlyError refuses operations that would resume or modify a core dump session
+func (c *Client) readOnlyError(operation string) error {
+	if c.coreFile == "" {
+		return nil
+	}
+	return fmt.Errorf("%s is not available when inspecting a core dump; use stacktrace, goroutines, eval_variable or the local variables instead", operation)
+}
+
+// createOpenCoreResponse creates a response for the open core command
+func (c *Client) createOpenCoreResponse(state *api.DebuggerState, coreFile string, executable string, err error) types.OpenCoreResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "open_core"
+
+	response := types.OpenCoreResponse{
+		Status:     "success",
+		Context:    &context,
+		CoreFile:   coreFile,
+		Executable: executable,
+	}
+	if err != nil {
+		response.Status = "error"
+		context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.Summary = fmt.Sprintf("Opened core dump of %s read-only", filepath.Base(executable))
+	if context.CurrentLocation != nil {
+		response.Summary += "; current position: " + *context.CurrentLocation
+	}
+	return response
+}
@@ -0,0 +1,161 @@
+package debugger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// dumpPollInterval is how long each wait for core dump progress lasts
+const dumpPollInterval = 500 * time.Millisecond
+
+// DumpCore writes a core file of the stopped program to dest, calling progress
+// while it is written. Binaries built for the session are removed on close, so
+// the executable is copied next to the core as dest+".bin" for open_core.
+// For connect sessions the core is written by the remote Delve server, so dest
+// is a path on its host, where the executable also stays.
+func (c *Client) DumpCore(dest string, progress func(types.DumpProgress)) types.DumpCoreResponse {
+	if c.client == nil {
+		return c.createDumpCoreResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return c.createDumpCoreResponse(nil, nil, c.exitedError())
+	}
+	if err := c.readOnlyError("dump_core"); err != nil {
+		return c.createDumpCoreResponse(nil, nil, err)
+	}
+
+	state, err := c.client.GetStateNonBlocking()
+	if err != nil {
+		return c.createDumpCoreResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.Running {
+		return c.createDumpCoreResponse(nil, nil, fmt.Errorf("the program is running; stop it at a breakpoint before dumping a core"))
+	}
+
+	absDest := dest
+	if c.IsRemote() {
+		if !filepath.IsAbs(dest) {
+			return c.createDumpCoreResponse(state, nil, fmt.Errorf("dest must be an absolute path on the host of the Delve server at %s", c.remoteAddr))
+		}
+	} else {
+		absDest, err = filepath.Abs(dest)
+		if err != nil {
+			return c.createDumpCoreResponse(state, nil, fmt.Errorf("failed to get absolute path: %v", err))
+		}
+		if _, err := os.Stat(absDest); err == nil {
+			return c.createDumpCoreResponse(state, nil, fmt.Errorf("%s already exists", absDest))
+		}
+	}
+
+	logger.Debug("Dumping core to %s", absDest)
+	start := time.Now()
+	dumpState, err := c.client.CoreDumpStart(absDest)
+	if err != nil {
+		return c.createDumpCoreResponse(state, nil, fmt.Errorf("failed to start core dump: %v", err))
+	}
+	for dumpState.Dumping {
+		if progress != nil {
+			progress(dumpProgress(dumpState))
+		}
+		dumpState = c.client.CoreDumpWait(int(dumpPollInterval / time.Millisecond))
+	}
+	if dumpState.Err != "" {
+		if !c.IsRemote() {
+			os.Remove(absDest)
+		}
+		return c.createDumpCoreResponse(state, nil, fmt.Errorf("core dump failed: %s", dumpState.Err))
+	}
+
+	result := &types.CoreDump{
+		Path:     absDest,
+		Threads:  dumpState.ThreadsTotal,
+		Memory:   dumpState.MemTotal,
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}
+	if c.IsRemote() {
+		// Neither the core nor the executable are on this host
+		return c.createDumpCoreResponse(state, result, nil)
+	}
+	if info, err := os.Stat(absDest); err == nil {
+		result.Size = info.Size()
+	}
+
+	result.Executable, err = c.preserveExecutable(absDest + ".bin")
+	if err != nil {
+		return c.createDumpCoreResponse(state, result, fmt.Errorf("core written, but the executable could not be kept: %v", err))
+	}
+
+	return c.createDumpCoreResponse(state, result, nil)
+}
+
+// preserveExecutable returns the binary to open the core with, copying it to
+// dest when the session built it and will remove it on close
+func (c *Client) preserveExecutable(dest string) (string, error) {
+	if c.builtBinary == "" {
+		// Launched and attached programs keep their binary
+		if c.target != "" {
+			return c.target, nil
+		}
+		return os.Readlink(filepath.Join(procDir, strconv.Itoa(c.pid), "exe"))
+	}
+
+	src, err := os.Open(c.builtBinary)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0755)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return "", err
+	}
+	return dest, dst.Close()
+}
+
+// dumpProgress converts Delve's dump state to a progress report
+func dumpProgress(state api.DumpState) types.DumpProgress {
+	return types.DumpProgress{
+		ThreadsDone:  state.ThreadsDone,
+		ThreadsTotal: state.ThreadsTotal,
+		MemDone:      state.MemDone,
+		MemTotal:     state.MemTotal,
+	}
+}
+
+// createDumpCoreResponse creates a response for the dump core command
+func (c *Client) createDumpCoreResponse(state *api.DebuggerState, dump *types.CoreDump, err error) types.DumpCoreResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "dump_core"
+
+	response := types.DumpCoreResponse{
+		Status:   "success",
+		Context:  context,
+		CoreDump: dump,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.Summary = fmt.Sprintf("Wrote a core dump of %d threads and %.1f MB of memory to %s in %s",
+		dump.Threads, float64(dump.Memory)/(1<<20), dump.Path, dump.Duration)
+	if c.IsRemote() {
+		response.Summary += fmt.Sprintf("; the core and the program's executable are on the host of the Delve server at %s, copy both to inspect them with open_core", c.remoteAddr)
+	} else {
+		response.Summary += "; inspect it with open_core using executable " + dump.Executable
+	}
+	return response
+}
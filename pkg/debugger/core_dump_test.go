@@ -0,0 +1,58 @@
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestPreserveExecutable(t *testing.T) {
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "debug_binary")
+	if err := os.WriteFile(binary, []byte("\x7fELF"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient()
+	c.target = binary
+	dest := filepath.Join(dir, "core.dump.bin")
+
+	// A binary the user launched stays where it is
+	if kept, err := c.preserveExecutable(dest); err != nil || kept != binary {
+		t.Fatalf("Expected the launched binary %s to be used, got %s (%v)", binary, kept, err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Fatalf("Expected the launched binary not to be copied: %v", err)
+	}
+
+	c.builtBinary = binary
+	kept, err := c.preserveExecutable(dest)
+	if err != nil || kept != dest {
+		t.Fatalf("Expected the binary to be copied to %s, got %s (%v)", dest, kept, err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "\x7fELF" {
+		t.Errorf("Expected the copy to match the binary, got %q (%v)", data, err)
+	}
+
+	// An existing file is never overwritten
+	if _, err := c.preserveExecutable(dest); err == nil {
+		t.Errorf("Expected copying over an existing file to fail")
+	}
+}
+
+func TestDumpCoreResponseForRemoteSession(t *testing.T) {
+	c := NewClient()
+	c.remoteAddr = "10.0.0.5:2345"
+	c.pid = 4242 // A PID on the server host, unrelated to local processes
+
+	response := c.createDumpCoreResponse(nil, &types.CoreDump{Path: "/var/tmp/app.core", Threads: 4, Duration: "1s"}, nil)
+	if response.Status != "success" {
+		t.Fatalf("Expected success, got %+v", response)
+	}
+	if !strings.Contains(response.Summary, "Delve server at 10.0.0.5:2345") || strings.Contains(response.Summary, "using executable") {
+		t.Errorf("Expected the summary to place the core and executable on the server host, got %q", response.Summary)
+	}
+}
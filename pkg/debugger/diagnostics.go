@@ -0,0 +1,85 @@
+package debugger
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// diagnosticPattern matches compiler and vet positions like "./main.go:12:5: message"
+var diagnosticPattern = regexp.MustCompile(`^(.+?\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// parseBuildOutput splits go build / go test -c output into compiler and vet diagnostics.
+// Relative file names are resolved against dir, the directory the build ran in.
+//
+// The go command groups messages under "# importpath" headers; vet findings from
+// test builds are grouped under "# [importpath]" or prefixed with "vet: ".
+func parseBuildOutput(output string, dir string) (buildErrors []types.BuildDiagnostic, vetErrors []types.BuildDiagnostic) {
+	var pkg string
+	var inVet bool
+	var last *types.BuildDiagnostic
+
+	add := func(d types.BuildDiagnostic, vet bool) {
+		if vet {
+			vetErrors = append(vetErrors, d)
+			last = &vetErrors[len(vetErrors)-1]
+		} else {
+			buildErrors = append(buildErrors, d)
+			last = &buildErrors[len(buildErrors)-1]
+		}
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		// Indented lines continue the previous message (e.g. "have/want" details)
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ") {
+			if last != nil {
+				last.Message += "\n" + strings.TrimSpace(line)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "# ") {
+			header := strings.TrimPrefix(line, "# ")
+			inVet = strings.HasPrefix(header, "[") && strings.HasSuffix(header, "]")
+			pkg = strings.Trim(header, "[]")
+			last = nil
+			continue
+		}
+
+		vet := inVet
+		if strings.HasPrefix(line, "vet: ") {
+			vet = true
+			line = strings.TrimPrefix(line, "vet: ")
+		}
+
+		match := diagnosticPattern.FindStringSubmatch(line)
+		if match == nil {
+			add(types.BuildDiagnostic{Message: line, Package: pkg}, vet)
+			continue
+		}
+
+		file := match[1]
+		if !filepath.IsAbs(file) && dir != "" {
+			file = filepath.Join(dir, file)
+		}
+		lineNo, _ := strconv.Atoi(match[2])
+		column, _ := strconv.Atoi(match[3])
+
+		add(types.BuildDiagnostic{
+			File:    file,
+			Line:    lineNo,
+			Column:  column,
+			Message: match[4],
+			Package: pkg,
+		}, vet)
+	}
+
+	return buildErrors, vetErrors
+}
@@ -0,0 +1,41 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestParseBuildOutput(t *testing.T) {
+	output := `# example.com/app
+./main.go:4:2: declared and not used: x
+./main.go:9:12: not enough arguments in call to process
+	have ()
+	want (int)
+/abs/util.go:7: undefined: helper
+# example.com/app
+# [example.com/app]
+./main_test.go:5:34: fmt.Printf format %d has arg "s" of wrong type string
+vet: ./other_test.go:3:8: could not import missing (invalid package name: "")
+`
+
+	buildErrors, vetErrors := parseBuildOutput(output, "/work/app")
+
+	expectedBuild := []types.BuildDiagnostic{
+		{File: "/work/app/main.go", Line: 4, Column: 2, Message: "declared and not used: x", Package: "example.com/app"},
+		{File: "/work/app/main.go", Line: 9, Column: 12, Message: "not enough arguments in call to process\nhave ()\nwant (int)", Package: "example.com/app"},
+		{File: "/abs/util.go", Line: 7, Message: "undefined: helper", Package: "example.com/app"},
+	}
+	if !reflect.DeepEqual(buildErrors, expectedBuild) {
+		t.Errorf("Expected build errors %+v, got %+v", expectedBuild, buildErrors)
+	}
+
+	expectedVet := []types.BuildDiagnostic{
+		{File: "/work/app/main_test.go", Line: 5, Column: 34, Message: `fmt.Printf format %d has arg "s" of wrong type string`, Package: "example.com/app"},
+		{File: "/work/app/other_test.go", Line: 3, Column: 8, Message: `could not import missing (invalid package name: "")`, Package: "example.com/app"},
+	}
+	if !reflect.DeepEqual(vetErrors, expectedVet) {
+		t.Errorf("Expected vet errors %+v, got %+v", expectedVet, vetErrors)
+	}
+}
@@ -0,0 +1,270 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// maxErrorChain bounds how many errors of a chain are reported
+const maxErrorChain = 32
+
+// maxErrorStack bounds the creation stack reported for each error
+const maxErrorStack = 10
+
+// errorChainConfig loads an error deep enough to walk its whole chain
+var errorChainConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 16,
+	MaxStringLen:       1024,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
+
+// errorLink is an error of a chain with the program counters of the stack it recorded
+type errorLink struct {
+	link types.ErrorLink
+	pcs  []uint64
+}
+
+// errorChain evaluates the error expr and reports it and every error it
+// wraps, with where each was created when it recorded a stack. Wrapped errors
+// are found heuristically in fields of type error or []error, which covers %w,
+// errors.Join, github.com/pkg/errors and most custom wrappers. With callUnwrap
+// they are what Unwrap returns, called in the target with function call
+// injection, falling back to the fields for errors where the call fails.
+func (c *Client) errorChain(scope api.EvalScope, expr string, callUnwrap bool) ([]types.ErrorLink, error) {
+	v, err := c.client.EvalVariable(scope, expr, errorChainConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %s: %v", expr, err)
+	}
+
+	var unwrap errorUnwrapper
+	var interrupted error
+	if callUnwrap {
+		unwrap = c.errorUnwrapper(scope, &interrupted)
+	}
+
+	var links []errorLink
+	walkErrorChain(v, 0, &links, c.errorLoader(scope), unwrap)
+	if interrupted != nil {
+		return nil, interrupted
+	}
+
+	chain := make([]types.ErrorLink, 0, len(links))
+	for _, link := range links {
+		for _, pc := range link.pcs {
+			if len(link.link.Stack) == maxErrorStack {
+				break
+			}
+			// Recorded PCs are return addresses, one past the call
+			locs, _, err := c.client.FindLocation(scope, fmt.Sprintf("*%#x", pc-1), false, nil)
+			if err != nil || len(locs) == 0 {
+				logger.Debug("Warning: Failed to find location of %#x: %v", pc, err)
+				continue
+			}
+			link.link.Stack = append(link.link.Stack, formatLocation(locs[0]))
+		}
+		if len(link.link.Stack) > 0 {
+			link.link.Location = link.link.Stack[0]
+		}
+		chain = append(chain, link.link)
+	}
+	return chain, nil
+}
+
+// errorLoader loads a variable the depth limit left unloaded again from its
+// address, so that chains of any length can be walked
+type errorLoader func(v *api.Variable) (*api.Variable, error)
+
+// errorLoader returns a loader evaluating variables by address in scope
+func (c *Client) errorLoader(scope api.EvalScope) errorLoader {
+	return func(v *api.Variable) (*api.Variable, error) {
+		if v.Addr == 0 {
+			return nil, fmt.Errorf("%s has no address", v.Type)
+		}
+		expr := fmt.Sprintf("*(*%s)(%#x)", delveTypeName(v.Type), v.Addr)
+		loaded, err := c.client.EvalVariable(scope, expr, errorChainConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate %s: %v", expr, err)
+		}
+		return loaded, nil
+	}
+}
+
+// errorUnwrapper calls the Unwrap method of an error and returns its results,
+// an error or a []error
+type errorUnwrapper func(v *api.Variable) ([]api.Variable, error)
+
+// errorUnwrapper returns an unwrapper injecting calls on the goroutine of
+// scope. Delve cannot abandon a call that stopped before returning, at a
+// breakpoint inside Unwrap, so the program stays stopped there: that is
+// recorded in interrupted and no further calls are made.
+func (c *Client) errorUnwrapper(scope api.EvalScope, interrupted *error) errorUnwrapper {
+	return func(v *api.Variable) ([]api.Variable, error) {
+		if *interrupted != nil {
+			return nil, *interrupted
+		}
+		expr, err := unwrapExpr(v)
+		if err != nil {
+			return nil, err
+		}
+
+		// Return values are only loaded for calls while a load config is set
+		c.client.SetReturnValuesLoadConfig(&errorChainConfig)
+		defer c.client.SetReturnValuesLoadConfig(nil)
+
+		state, err := c.client.Call(scope.GoroutineID, expr, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call %s: %v", expr, err)
+		}
+		if state.Exited {
+			*interrupted = fmt.Errorf("the program exited while calling %s", expr)
+			return nil, *interrupted
+		}
+		if state.CurrentThread == nil || !state.CurrentThread.CallReturn {
+			where := "an unknown location"
+			if thread := state.CurrentThread; thread != nil {
+				where = fmt.Sprintf("%s:%d", thread.File, thread.Line)
+			}
+			*interrupted = fmt.Errorf("call %s did not return: the program is stopped inside it at %s; continue to finish the call and return to where the program was", expr, where)
+			return nil, *interrupted
+		}
+		return state.CurrentThread.ReturnValues, nil
+	}
+}
+
+// unwrapExpr returns an expression calling the Unwrap method of v by address.
+// Pointers are called through the address they hold, which, unlike where
+// they are stored, stays valid for values returned by an earlier call.
+func unwrapExpr(v *api.Variable) (string, error) {
+	if v.Kind == reflect.Ptr {
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			return "", fmt.Errorf("%s has no address", v.Type)
+		}
+		return fmt.Sprintf("(%s)(%#x).Unwrap()", delveTypeName(v.Type), v.Children[0].Addr), nil
+	}
+	if v.Addr == 0 {
+		return "", fmt.Errorf("%s has no address", v.Type)
+	}
+	return fmt.Sprintf("(*(*%s)(%#x)).Unwrap()", delveTypeName(v.Type), v.Addr), nil
+}
+
+// walkErrorChain adds the error held by the interface v, then the errors it
+// wraps depth first, reloading those left unloaded with load. Wrapped errors
+// are what unwrap returns and, when it fails or is nil, fields of type error
+// or []error.
+func walkErrorChain(v *api.Variable, depth int, links *[]errorLink, load errorLoader, unwrap errorUnwrapper) {
+	if len(*links) == maxErrorChain || v.Kind != reflect.Interface || isNilInterface(v) {
+		return
+	}
+
+	concrete := reload(&v.Children[0], load)
+	link := errorLink{link: types.ErrorLink{Depth: depth, Type: concrete.Type}}
+	if message, _, ok := errorMessage(concrete); ok {
+		link.link.Message = message
+	}
+	value := concrete
+	if concrete.Kind == reflect.Ptr && len(concrete.Children) > 0 {
+		value = &concrete.Children[0]
+	}
+	link.pcs = stackPCs(value)
+	*links = append(*links, link)
+
+	if unwrap != nil {
+		wrapped, err := unwrap(concrete)
+		if err == nil {
+			walkWrappedErrors(wrapped, depth+1, links, load, unwrap)
+			return
+		}
+		logger.Debug("Warning: Failed to unwrap %s: %v", concrete.Type, err)
+	}
+	walkWrappedErrors(value.Children, depth+1, links, load, unwrap)
+}
+
+// walkWrappedErrors walks the chains of the variables of type error or []error in vars
+func walkWrappedErrors(vars []api.Variable, depth int, links *[]errorLink, load errorLoader, unwrap errorUnwrapper) {
+	for i := range vars {
+		f := &vars[i]
+		switch f.Type {
+		case "error":
+			walkErrorChain(f, depth, links, load, unwrap)
+		case "[]error":
+			f = reload(f, load)
+			for j := range f.Children {
+				walkErrorChain(&f.Children[j], depth, links, load, unwrap)
+			}
+		}
+	}
+}
+
+// reload returns v loaded again with load when the depth limit left its
+// value unloaded, or v itself
+func reload(v *api.Variable, load errorLoader) *api.Variable {
+	if load == nil || !unloaded(v) {
+		return v
+	}
+	loaded, err := load(v)
+	if err != nil {
+		logger.Debug("Warning: Failed to load %s: %v", v.Type, err)
+		return v
+	}
+	return loaded
+}
+
+// unloaded reports whether the value of v, or of what it points to, was not
+// loaded because it lies past the depth limit
+func unloaded(v *api.Variable) bool {
+	if v.OnlyAddr {
+		return true
+	}
+	if v.Kind == reflect.Ptr {
+		if len(v.Children) == 0 {
+			return true
+		}
+		if pointerTarget(v) == 0 {
+			return false
+		}
+		v = &v.Children[0]
+		if v.OnlyAddr {
+			return true
+		}
+	}
+	switch v.Kind {
+	case reflect.Struct, reflect.Slice, reflect.Array:
+		return len(v.Children) == 0 && v.Len > 0
+	}
+	return false
+}
+
+// stackPCs returns the program counters an error recorded where it was
+// created, like the stack field of github.com/pkg/errors
+func stackPCs(v *api.Variable) []uint64 {
+	stack := field(v, "stack")
+	if stack == nil {
+		return nil
+	}
+	if stack.Kind == reflect.Ptr {
+		if pointerTarget(stack) == 0 {
+			return nil
+		}
+		stack = &stack.Children[0]
+	}
+	if stack.Kind != reflect.Slice && stack.Kind != reflect.Array {
+		return nil
+	}
+
+	var pcs []uint64
+	for _, child := range stack.Children {
+		pc, err := strconv.ParseUint(child.Value, 0, 64)
+		if err != nil || pc == 0 {
+			continue
+		}
+		pcs = append(pcs, pc)
+	}
+	return pcs
+}
@@ -0,0 +1,159 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestWalkErrorChain(t *testing.T) {
+	iface := func(concrete api.Variable) api.Variable {
+		return api.Variable{Type: "error", Kind: reflect.Interface, Children: []api.Variable{concrete}}
+	}
+	ptr := func(typeName string, fields ...api.Variable) api.Variable {
+		return api.Variable{Type: "*" + typeName, Kind: reflect.Ptr, Children: []api.Variable{
+			{Type: typeName, Kind: reflect.Struct, Children: fields},
+		}}
+	}
+	str := func(name, value string) api.Variable {
+		return api.Variable{Name: name, Type: "string", Kind: reflect.String, Value: value}
+	}
+	named := func(name string, v api.Variable) api.Variable {
+		v.Name = name
+		return v
+	}
+
+	// errors.Join(fmt.Errorf("save: %w", errors.WithMessage(errors.New("disk full"), "write")), io.EOF)
+	stack := api.Variable{Name: "stack", Type: "*github.com/pkg/errors.stack", Kind: reflect.Ptr, Children: []api.Variable{
+		{Kind: reflect.Slice, Children: []api.Variable{{Value: "4660"}, {Value: "4700"}}},
+	}}
+	stack.Children[0].Addr = 0xc000010000 // A non-nil pointer
+	fundamental := ptr("github.com/pkg/errors.fundamental", str("msg", "disk full"), stack)
+	withMessage := ptr("github.com/pkg/errors.withMessage", named("cause", iface(fundamental)), str("msg", "write"))
+	wrapError := ptr("fmt.wrapError", str("msg", "save: write: disk full"), named("err", iface(withMessage)))
+	eof := ptr("errors.errorString", str("s", "EOF"))
+	join := ptr("errors.joinError", api.Variable{Name: "errs", Type: "[]error", Kind: reflect.Slice, Children: []api.Variable{iface(wrapError), iface(eof)}})
+	root := iface(join)
+
+	var links []errorLink
+	walkErrorChain(&root, 0, &links, nil, nil)
+
+	want := []struct {
+		depth   int
+		typ     string
+		message string
+	}{
+		{0, "*errors.joinError", "save: write: disk full\nEOF"},
+		{1, "*fmt.wrapError", "save: write: disk full"},
+		{2, "*github.com/pkg/errors.withMessage", "write: disk full"},
+		{3, "*github.com/pkg/errors.fundamental", "disk full"},
+		{1, "*errors.errorString", "EOF"},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("Expected %d links, got %+v", len(want), links)
+	}
+	for i, w := range want {
+		got := links[i].link
+		if got.Depth != w.depth || got.Type != w.typ || got.Message != w.message {
+			t.Errorf("Link %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+	if !reflect.DeepEqual(links[3].pcs, []uint64{4660, 4700}) {
+		t.Errorf("Expected the recorded stack of the fundamental error, got %v", links[3].pcs)
+	}
+}
+
+func TestWalkErrorChainReloadsPastDepthLimit(t *testing.T) {
+	// A chain of fmt.wrapError whose inner errors were left unloaded at the
+	// depth limit, as Delve leaves them beyond MaxVariableRecurse
+	const length = 12
+	unloadedError := func(addr uint64) api.Variable {
+		return api.Variable{Name: "err", Type: "error", Kind: reflect.Interface, Children: []api.Variable{
+			{Type: "*fmt.wrapError", Kind: reflect.Ptr, Addr: addr, OnlyAddr: true},
+		}}
+	}
+	loaded := func(addr uint64) *api.Variable {
+		fields := []api.Variable{{Name: "msg", Type: "string", Kind: reflect.String, Value: fmt.Sprintf("level %d", addr)}}
+		if addr < length {
+			fields = append(fields, unloadedError(addr+1))
+		}
+		return &api.Variable{Type: "*fmt.wrapError", Kind: reflect.Ptr, Children: []api.Variable{
+			{Type: "fmt.wrapError", Kind: reflect.Struct, Addr: 0xc000000000 + addr, Len: int64(len(fields)), Children: fields},
+		}}
+	}
+	var loads int
+	load := func(v *api.Variable) (*api.Variable, error) {
+		loads++
+		return loaded(v.Addr), nil
+	}
+
+	root := unloadedError(1)
+	var links []errorLink
+	walkErrorChain(&root, 0, &links, load, nil)
+
+	if len(links) != length || loads != length {
+		t.Fatalf("Expected %d links from %d loads, got %d links from %d loads", length, length, len(links), loads)
+	}
+	if last := links[length-1].link; last.Depth != length-1 || last.Message != fmt.Sprintf("level %d", length) {
+		t.Errorf("Expected the innermost error at depth %d, got %+v", length-1, last)
+	}
+
+	// Without a loader the walk stops at the first unloaded error
+	links = nil
+	walkErrorChain(&root, 0, &links, nil, nil)
+	if len(links) != 1 || links[0].link.Message != "" {
+		t.Errorf("Expected only the unloaded outer error, got %+v", links)
+	}
+}
+
+func TestWalkErrorChainUnwraps(t *testing.T) {
+	iface := func(concrete api.Variable) api.Variable {
+		return api.Variable{Type: "error", Kind: reflect.Interface, Children: []api.Variable{concrete}}
+	}
+	ptr := func(typeName string, addr uint64, fields ...api.Variable) api.Variable {
+		return api.Variable{Type: "*" + typeName, Kind: reflect.Ptr, Children: []api.Variable{
+			{Type: typeName, Kind: reflect.Struct, Addr: addr, Children: fields},
+		}}
+	}
+
+	// A lookupError whose Unwrap looks its cause up in a table, wrapping an
+	// error whose Unwrap can't be called but which holds its cause in a field
+	eof := ptr("errors.errorString", 0xc3, api.Variable{Name: "s", Type: "string", Kind: reflect.String, Value: "EOF"})
+	noCall := ptr("main.noCallError", 0xc2, api.Variable{Name: "cause", Type: "error", Kind: reflect.Interface, Children: []api.Variable{eof}})
+	lookup := ptr("main.lookupError", 0xc1, api.Variable{Name: "id", Type: "int", Kind: reflect.Int, Value: "7"})
+	root := iface(lookup)
+
+	var calls []string
+	unwrap := func(v *api.Variable) ([]api.Variable, error) {
+		expr, err := unwrapExpr(v)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, expr)
+		switch v.Type {
+		case "*main.lookupError":
+			return []api.Variable{iface(noCall)}, nil
+		case "*errors.errorString":
+			return nil, fmt.Errorf("%s has no method Unwrap", v.Type)
+		}
+		return nil, fmt.Errorf("call injection not supported")
+	}
+
+	var links []errorLink
+	walkErrorChain(&root, 0, &links, nil, unwrap)
+
+	want := []string{"*main.lookupError", "*main.noCallError", "*errors.errorString"}
+	if len(links) != len(want) {
+		t.Fatalf("Expected %d links, got %+v", len(want), links)
+	}
+	for i, typ := range want {
+		if got := links[i].link; got.Type != typ || got.Depth != i {
+			t.Errorf("Link %d: expected %s at depth %d, got %+v", i, typ, i, got)
+		}
+	}
+	if len(calls) != 3 || calls[0] != "(*main.lookupError)(0xc1).Unwrap()" {
+		t.Errorf("Expected Unwrap called on each error by address, got %v", calls)
+	}
+}
@@ -14,19 +14,77 @@ func (c *Client) Continue() types.ContinueResponse {
 	if c.client == nil {
 		return c.createContinueResponse(nil, fmt.Errorf("no active debug session"))
 	}
+	if c.exited {
+		return c.createContinueResponse(nil, c.exitedError())
+	}
+	if err := c.readOnlyError("continue"); err != nil {
+		return c.createContinueResponse(nil, err)
+	}
+
+	// A line matched while the program was stopped is reported without resuming
+	if hit := c.takeOutputHit(); hit != nil {
+		delveState, err := c.client.GetState()
+		if err != nil {
+			return c.createContinueResponse(nil, fmt.Errorf("failed to get state: %v", err))
+		}
+		response := c.createContinueResponse(delveState, nil)
+		response.OutputBreak = c.describeOutputHit(hit)
+		response.Context.StopReason = fmt.Sprintf("output matched %s", hit.pattern)
+		return response
+	}
 
 	logger.Debug("Continuing execution")
 
-	// Continue returns a channel that will receive state updates
-	stateChan := c.client.Continue()
+	delveState, _, err := c.resume(0)
+	if err != nil {
+		return c.createContinueResponse(nil, err)
+	}
 
-	// Wait for the state update from the channel
-	delveState := <-stateChan
-	if delveState.Err != nil {
-		return c.createContinueResponse(nil, fmt.Errorf("continue command failed: %v", delveState.Err))
+	response := c.createContinueResponse(delveState, nil)
+	if hit := c.takeOutputHit(); hit != nil && !response.Context.Exited {
+		response.OutputBreak = c.describeOutputHit(hit)
+		response.Context.StopReason = fmt.Sprintf("output matched %s", hit.pattern)
 	}
+	return response
+}
+
+// resume continues the program until it stops at something other than a
+// failure breakpoint inside package testing. While it runs, break_on_output can
+// halt it. A non-zero timeout halts it once elapsed, reported by timedOut.
+func (c *Client) resume(timeout time.Duration) (state *api.DebuggerState, timedOut bool, err error) {
+	c.continuing.Store(true)
+	defer c.continuing.Store(false)
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		// Continue returns a channel that will receive state updates
+		stateChan := c.client.Continue()
+		select {
+		case state = <-stateChan:
+		case <-deadline:
+			logger.Debug("Halting program still running after %v", timeout)
+			if _, err := c.client.Halt(); err != nil {
+				logger.Debug("Warning: Failed to halt program: %v", err)
+			}
+			state = <-stateChan
+			// It may have stopped on its own just before the halt
+			timedOut = state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil
+		}
 
-	return c.createContinueResponse(delveState, nil)
+		// A process exit also carries an error, but it is a normal end of execution
+		if state.Err != nil && !state.Exited {
+			return nil, false, fmt.Errorf("continue command failed: %v", state.Err)
+		}
+		if timedOut || !c.isInternalFailureStop(state) {
+			return state, timedOut, nil
+		}
+	}
 }
 
 // Step executes a single instruction, stepping into function calls
@@ -34,6 +92,12 @@ func (c *Client) Step() types.StepResponse {
 	if c.client == nil {
 		return c.createStepResponse(nil, "into", nil, fmt.Errorf("no active debug session"))
 	}
+	if c.exited {
+		return c.createStepResponse(nil, "into", nil, c.exitedError())
+	}
+	if err := c.readOnlyError("step"); err != nil {
+		return c.createStepResponse(nil, "into", nil, err)
+	}
 
 	// Check if program is running or not stopped
 	delveState, err := c.client.GetState()
@@ -66,6 +130,12 @@ func (c *Client) StepOver() types.StepResponse {
 	if c.client == nil {
 		return c.createStepResponse(nil, "over", nil, fmt.Errorf("no active debug session"))
 	}
+	if c.exited {
+		return c.createStepResponse(nil, "over", nil, c.exitedError())
+	}
+	if err := c.readOnlyError("step"); err != nil {
+		return c.createStepResponse(nil, "over", nil, err)
+	}
 
 	// Check if program is running or not stopped
 	delveState, err := c.client.GetState()
@@ -98,6 +168,12 @@ func (c *Client) StepOut() types.StepResponse {
 	if c.client == nil {
 		return c.createStepResponse(nil, "out", nil, fmt.Errorf("no active debug session"))
 	}
+	if c.exited {
+		return c.createStepResponse(nil, "out", nil, c.exitedError())
+	}
+	if err := c.readOnlyError("step"); err != nil {
+		return c.createStepResponse(nil, "out", nil, err)
+	}
 
 	// Check if program is running or not stopped
 	delveState, err := c.client.GetState()
@@ -137,8 +213,9 @@ func (c *Client) createContinueResponse(state *api.DebuggerState, err error) typ
 	}
 
 	return types.ContinueResponse{
-		Status:  "success",
-		Context: context,
+		Status:      "success",
+		Context:     context,
+		TestFailure: c.testFailure(state),
 	}
 }
 
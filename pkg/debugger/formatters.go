@@ -0,0 +1,403 @@
+package debugger
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// Formatter renders a variable of a known type readably. It reports false
+// when the variable was not loaded deeply enough to be formatted.
+type Formatter func(v *api.Variable) (string, bool)
+
+// formattersMu guards formatters, which eval and locals read while
+// RegisterFormatter may write
+var formattersMu sync.RWMutex
+
+// formatters maps type names, as Delve reports them, to their formatter
+var formatters = map[string]Formatter{
+	"time.Time":                formatTime,
+	"time.Duration":            formatDuration,
+	"math/big.Int":             formatBigInt,
+	"net.IP":                   formatIP,
+	"net/url.URL":              formatURL,
+	"encoding/json.RawMessage": formatRawMessage,
+	"[]uint8":                  formatBytes,
+	"error":                    formatError,
+}
+
+// RegisterFormatter sets the formatter for a type name like "time.Time",
+// replacing any existing one. Pointers to the type are formatted too.
+func RegisterFormatter(typeName string, formatter Formatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	formatters[typeName] = formatter
+}
+
+// lookupFormatter returns the formatter registered for a type name
+func lookupFormatter(typeName string) (Formatter, bool) {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	formatter, ok := formatters[typeName]
+	return formatter, ok
+}
+
+// formatVariable formats v with the formatter for its type, if there is one
+func formatVariable(v *api.Variable) (string, bool) {
+	if formatter, ok := lookupFormatter(v.Type); ok {
+		return formatter(v)
+	}
+	if v.Kind == reflect.Ptr && len(v.Children) > 0 {
+		if formatter, ok := lookupFormatter(strings.TrimPrefix(v.Type, "*")); ok {
+			if pointerTarget(v) == 0 {
+				return "nil", true
+			}
+			return formatter(&v.Children[0])
+		}
+	}
+	return "", false
+}
+
+// variableValue returns the value of a nested variable, formatted when its type has a formatter
+func variableValue(v *api.Variable) string {
+	if formatted, ok := formatVariable(v); ok {
+		return formatted
+	}
+	return v.Value
+}
+
+// field returns the loaded field of a struct with the given name
+func field(v *api.Variable, name string) *api.Variable {
+	for i := range v.Children {
+		if v.Children[i].Name == name {
+			return &v.Children[i]
+		}
+	}
+	return nil
+}
+
+// Constants of time.Time's wall and ext fields, see $GOROOT/src/time/time.go
+const (
+	wallHasMonotonic = 1 << 63
+	wallNsecShift    = 30
+	wallToUnix       = -2682288000 // Seconds from 1885, the wall epoch, to 1970
+	absoluteToUnix   = -62135596800
+)
+
+// formatTime formats a time.Time in RFC 3339
+func formatTime(v *api.Variable) (string, bool) {
+	// Delve already decodes times, including their location, followed by the monotonic reading
+	if v.Value != "" {
+		value, _, _ := strings.Cut(v.Value, ", ")
+		return value, true
+	}
+
+	wallVar, extVar := field(v, "wall"), field(v, "ext")
+	if wallVar == nil || extVar == nil {
+		return "", false
+	}
+	wall, err := strconv.ParseUint(wallVar.Value, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	ext, err := strconv.ParseInt(extVar.Value, 10, 64)
+	if err != nil {
+		return "", false
+	}
+
+	nsec := int64(wall & (1<<wallNsecShift - 1))
+	var t time.Time
+	if wall&wallHasMonotonic != 0 {
+		sec := int64(wall << 1 >> (wallNsecShift + 1))
+		t = time.Unix(sec+wallToUnix, nsec)
+	} else {
+		t = time.Unix(ext+absoluteToUnix, nsec)
+	}
+	return t.UTC().Format(time.RFC3339Nano), true
+}
+
+// formatDuration formats a time.Duration like "1.5s"
+func formatDuration(v *api.Variable) (string, bool) {
+	value := v.Value
+	// Values equal to a constant read like "time.Second (1000000000)"
+	if open := strings.LastIndex(value, "("); open >= 0 && strings.HasSuffix(value, ")") {
+		value = value[open+1 : len(value)-1]
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	return time.Duration(n).String(), true
+}
+
+// formatBigInt formats a math/big.Int in decimal from its words
+func formatBigInt(v *api.Variable) (string, bool) {
+	neg, abs := field(v, "neg"), field(v, "abs")
+	if neg == nil || abs == nil || int64(len(abs.Children)) != abs.Len {
+		return "", false
+	}
+
+	n := new(big.Int)
+	for i := len(abs.Children) - 1; i >= 0; i-- {
+		word, err := strconv.ParseUint(abs.Children[i].Value, 10, 64)
+		if err != nil {
+			return "", false
+		}
+		n.Lsh(n, 64)
+		n.Or(n, new(big.Int).SetUint64(word))
+	}
+	if neg.Value == "true" {
+		n.Neg(n)
+	}
+	return n.String(), true
+}
+
+// formatIP formats a net.IP in dotted or colon notation
+func formatIP(v *api.Variable) (string, bool) {
+	b, ok := byteValues(v)
+	if !ok {
+		return "", false
+	}
+	if len(b) == 0 {
+		return "<nil>", true
+	}
+	return net.IP(b).String(), true
+}
+
+// formatURL formats a net/url.URL as the URL it represents
+func formatURL(v *api.Variable) (string, bool) {
+	if len(v.Children) == 0 {
+		return "", false
+	}
+	str := func(name string) string {
+		if f := field(v, name); f != nil {
+			return f.Value
+		}
+		return ""
+	}
+	u := url.URL{
+		Scheme:   str("Scheme"),
+		Opaque:   str("Opaque"),
+		Host:     str("Host"),
+		Path:     str("Path"),
+		RawPath:  str("RawPath"),
+		RawQuery: str("RawQuery"),
+		Fragment: str("Fragment"),
+	}
+	// The password is left out on purpose
+	if user := field(v, "User"); user != nil && pointerTarget(user) != 0 && len(user.Children[0].Children) > 0 {
+		if username := field(&user.Children[0], "username"); username != nil {
+			u.User = url.User(username.Value)
+		}
+	}
+	return u.String(), true
+}
+
+// formatRawMessage formats a encoding/json.RawMessage as its JSON text
+func formatRawMessage(v *api.Variable) (string, bool) {
+	b, ok := byteValues(v)
+	if !ok {
+		return "", false
+	}
+	value := string(b)
+	if int64(len(b)) < v.Len {
+		value += fmt.Sprintf("…(%d bytes)", v.Len)
+	}
+	return value, true
+}
+
+// formatBytes formats a []byte as a quoted string when it is valid UTF-8 text, else as hex
+func formatBytes(v *api.Variable) (string, bool) {
+	b, ok := byteValues(v)
+	if !ok {
+		return "", false
+	}
+	var value string
+	if utf8.Valid(b) && isText(b) {
+		value = strconv.Quote(string(b))
+	} else {
+		value = fmt.Sprintf("%x", b)
+	}
+	if int64(len(b)) < v.Len {
+		value += fmt.Sprintf("…(%d bytes)", v.Len)
+	}
+	return value, true
+}
+
+// isText reports whether b has no control characters other than whitespace
+func isText(b []byte) bool {
+	for _, r := range string(b) {
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// byteValues returns the loaded elements of a byte slice
+func byteValues(v *api.Variable) ([]byte, bool) {
+	if v.Kind != reflect.Slice && v.Kind != reflect.Array {
+		return nil, false
+	}
+	b := make([]byte, 0, len(v.Children))
+	for _, child := range v.Children {
+		n, err := strconv.ParseUint(child.Value, 10, 8)
+		if err != nil {
+			return nil, false
+		}
+		b = append(b, byte(n))
+	}
+	return b, true
+}
+
+// formatError formats an error like its Error method would, followed by the
+// concrete types of its wrapping chain
+func formatError(v *api.Variable) (string, bool) {
+	if v.Kind != reflect.Interface {
+		return "", false
+	}
+	if isNilInterface(v) {
+		return "nil", true
+	}
+	message, chain, ok := errorMessage(&v.Children[0])
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s (%s)", message, strings.Join(chain, " → ")), true
+}
+
+// isNilInterface reports whether an interface variable holds nothing
+func isNilInterface(v *api.Variable) bool {
+	return len(v.Children) == 0 || v.Children[0].Kind == reflect.Invalid || v.Children[0].Type == ""
+}
+
+// wrappedErrorFields are the fields custom errors commonly keep a wrapped error in
+var wrappedErrorFields = []string{"err", "Err", "cause", "error"}
+
+// errorMessage reconstructs the message of the concrete value of an error
+// for the error types of the standard library, and returns the concrete
+// types of the error and those it wraps
+func errorMessage(v *api.Variable) (string, []string, bool) {
+	chain := []string{v.Type}
+	value := v
+	if v.Kind == reflect.Ptr {
+		if len(v.Children) == 0 || len(v.Children[0].Children) == 0 {
+			return "", nil, false
+		}
+		value = &v.Children[0]
+	}
+
+	// wrapped returns the message and chain of the error in a field
+	wrapped := func(name string) (string, bool) {
+		inner := field(value, name)
+		if inner == nil || inner.Kind != reflect.Interface || isNilInterface(inner) {
+			return "", false
+		}
+		message, innerChain, ok := errorMessage(&inner.Children[0])
+		if ok {
+			chain = append(chain, innerChain...)
+		}
+		return message, ok
+	}
+
+	switch strings.TrimPrefix(v.Type, "*") {
+	case "errors.errorString":
+		if s := field(value, "s"); s != nil {
+			return s.Value, chain, true
+		}
+	case "fmt.wrapError":
+		if msg := field(value, "msg"); msg != nil {
+			// The message already contains the wrapped error's
+			wrapped("err")
+			return msg.Value, chain, true
+		}
+	case "fmt.wrapErrors":
+		if msg := field(value, "msg"); msg != nil {
+			// Errorf with several %w keeps them all, and its message already contains theirs
+			if errs := field(value, "errs"); errs != nil {
+				for i := range errs.Children {
+					if isNilInterface(&errs.Children[i]) {
+						continue
+					}
+					if _, innerChain, ok := errorMessage(&errs.Children[i].Children[0]); ok {
+						chain = append(chain, innerChain...)
+					}
+				}
+			}
+			return msg.Value, chain, true
+		}
+	case "errors.joinError":
+		if errs := field(value, "errs"); errs != nil {
+			var messages []string
+			for i := range errs.Children {
+				if isNilInterface(&errs.Children[i]) {
+					continue
+				}
+				message, innerChain, ok := errorMessage(&errs.Children[i].Children[0])
+				if !ok {
+					return "", nil, false
+				}
+				messages = append(messages, message)
+				chain = append(chain, innerChain...)
+			}
+			return strings.Join(messages, "\n"), chain, true
+		}
+	case "io/fs.PathError", "os.PathError":
+		op, path := field(value, "Op"), field(value, "Path")
+		if message, ok := wrapped("Err"); ok && op != nil && path != nil {
+			return op.Value + " " + path.Value + ": " + message, chain, true
+		}
+	case "os.SyscallError":
+		if syscallName := field(value, "Syscall"); syscallName != nil {
+			if message, ok := wrapped("Err"); ok {
+				return syscallName.Value + ": " + message, chain, true
+			}
+		}
+	case "syscall.Errno":
+		if n, err := strconv.ParseUint(value.Value, 10, 64); err == nil {
+			return syscall.Errno(n).Error(), chain, true
+		}
+	default:
+		// Custom errors usually keep their message in a field, and prefix
+		// the message of the error they wrap, as github.com/pkg/errors does
+		var own string
+		for _, name := range []string{"msg", "message", "Msg", "Message"} {
+			if msg := field(value, name); msg != nil && msg.Kind == reflect.String {
+				own = msg.Value
+				break
+			}
+		}
+		for _, name := range wrappedErrorFields {
+			if message, ok := wrapped(name); ok {
+				if own == "" {
+					return message, chain, true
+				}
+				return own + ": " + message, chain, true
+			}
+		}
+		if own != "" {
+			return own, chain, true
+		}
+		if value.Value != "" {
+			return v.Type + "(" + value.Value + ")", chain, true
+		}
+		if len(value.Children) > 0 {
+			fields := make([]string, 0, len(value.Children))
+			for _, f := range value.Children {
+				fields = append(fields, f.Name+":"+f.Value)
+			}
+			return v.Type + "{" + strings.Join(fields, ", ") + "}", chain, true
+		}
+	}
+	return "", nil, false
+}
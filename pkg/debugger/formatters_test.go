@@ -0,0 +1,90 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// byteSlice builds a loaded []uint8 variable of the given type
+func byteSlice(typeName string, b []byte) api.Variable {
+	v := api.Variable{Type: typeName, Kind: reflect.Slice, Len: int64(len(b))}
+	for _, c := range b {
+		v.Children = append(v.Children, api.Variable{Kind: reflect.Uint8, Value: fmt.Sprint(c)})
+	}
+	return v
+}
+
+func TestFormatVariable(t *testing.T) {
+	str := func(name, value string) api.Variable {
+		return api.Variable{Name: name, Kind: reflect.String, Value: value}
+	}
+	tests := []struct {
+		name string
+		v    api.Variable
+		want string
+	}{
+		{"time", api.Variable{Type: "time.Time", Kind: reflect.Struct, Value: "2024-05-01T10:00:00Z, +1234"}, "2024-05-01T10:00:00Z"},
+		{"duration", api.Variable{Type: "time.Duration", Kind: reflect.Int64, Value: "1500000000"}, "1.5s"},
+		{"duration constant", api.Variable{Type: "time.Duration", Kind: reflect.Int64, Value: "time.Second (1000000000)"}, "1s"},
+		{"big int", api.Variable{Type: "math/big.Int", Kind: reflect.Struct, Children: []api.Variable{
+			{Name: "neg", Kind: reflect.Bool, Value: "true"},
+			{Name: "abs", Kind: reflect.Slice, Len: 2, Children: []api.Variable{{Value: "0"}, {Value: "1"}}},
+		}}, "-18446744073709551616"},
+		{"ip", byteSlice("net.IP", []byte{10, 0, 0, 1}), "10.0.0.1"},
+		{"url", api.Variable{Type: "net/url.URL", Kind: reflect.Struct, Children: []api.Variable{
+			str("Scheme", "https"), str("Host", "example.com"), str("Path", "/a b"), str("RawQuery", "q=1"),
+		}}, "https://example.com/a%20b?q=1"},
+		{"raw json", byteSlice("encoding/json.RawMessage", []byte(`{"a":1}`)), `{"a":1}`},
+		{"text bytes", byteSlice("[]uint8", []byte("hello")), `"hello"`},
+		{"binary bytes", byteSlice("[]uint8", []byte{0, 0xff}), "00ff"},
+	}
+	for _, tt := range tests {
+		got, ok := formatVariable(&tt.v)
+		if !ok || got != tt.want {
+			t.Errorf("%s: got %q (%v), want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestFormatError(t *testing.T) {
+	errorString := api.Variable{Type: "*errors.errorString", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "errors.errorString", Kind: reflect.Struct, Children: []api.Variable{{Name: "s", Kind: reflect.String, Value: "file missing"}}},
+	}}
+	wrapped := api.Variable{Type: "error", Kind: reflect.Interface, Children: []api.Variable{
+		{Type: "*fmt.wrapError", Kind: reflect.Ptr, Children: []api.Variable{
+			{Type: "fmt.wrapError", Kind: reflect.Struct, Children: []api.Variable{
+				{Name: "msg", Kind: reflect.String, Value: "load config: file missing"},
+				{Name: "err", Type: "error", Kind: reflect.Interface, Children: []api.Variable{errorString}},
+			}},
+		}},
+	}}
+
+	want := "load config: file missing (*fmt.wrapError → *errors.errorString)"
+	if got, ok := formatVariable(&wrapped); !ok || got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Errorf with several %w keeps them in errs
+	iface := func(v api.Variable) api.Variable {
+		return api.Variable{Type: "error", Kind: reflect.Interface, Children: []api.Variable{v}}
+	}
+	wrappedMany := iface(api.Variable{Type: "*fmt.wrapErrors", Kind: reflect.Ptr, Children: []api.Variable{
+		{Type: "fmt.wrapErrors", Kind: reflect.Struct, Children: []api.Variable{
+			{Name: "msg", Kind: reflect.String, Value: "load config: file missing, file missing"},
+			{Name: "errs", Type: "[]error", Kind: reflect.Slice, Children: []api.Variable{iface(errorString), iface(errorString)}},
+		}},
+	}})
+
+	want = "load config: file missing, file missing (*fmt.wrapErrors → *errors.errorString → *errors.errorString)"
+	if got, ok := formatVariable(&wrappedMany); !ok || got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	nilErr := api.Variable{Type: "error", Kind: reflect.Interface}
+	if got, ok := formatVariable(&nilErr); !ok || got != "nil" {
+		t.Errorf("Expected nil, got %q", got)
+	}
+}
@@ -0,0 +1,219 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// goroutinePageSize is how many goroutines are requested from Delve at a time
+const goroutinePageSize = 1000
+
+// ListGoroutines lists all goroutines with their status and location
+func (c *Client) ListGoroutines() types.GoroutinesResponse {
+	if c.client == nil {
+		return c.createGoroutinesResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return c.createGoroutinesResponse(nil, nil, c.exitedError())
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createGoroutinesResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	goroutines, err := c.allGoroutines()
+	if err != nil {
+		return c.createGoroutinesResponse(state, nil, err)
+	}
+
+	reasons := c.waitReasons()
+	var result []types.Goroutine
+	for _, g := range goroutines {
+		result = append(result, convertGoroutine(g, state, reasons))
+	}
+	return c.createGoroutinesResponse(state, result, nil)
+}
+
+// Stacktrace returns the call stack of a goroutine, or of the selected one for
+// ID 0, optionally with the arguments and locals of each frame
+func (c *Client) Stacktrace(goroutineID int64, depth int, withLocals bool) types.StacktraceResponse {
+	if c.client == nil {
+		return c.createStacktraceResponse(nil, 0, nil, fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return c.createStacktraceResponse(nil, 0, nil, c.exitedError())
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createStacktraceResponse(nil, 0, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if goroutineID == 0 {
+		if state.SelectedGoroutine == nil {
+			return c.createStacktraceResponse(state, 0, nil, fmt.Errorf("no goroutine selected"))
+		}
+		goroutineID = state.SelectedGoroutine.ID
+	}
+	if depth <= 0 {
+		depth = 50
+	}
+
+	var cfg *api.LoadConfig
+	if withLocals {
+		cfg = &api.LoadConfig{
+			FollowPointers:     true,
+			MaxVariableRecurse: 1,
+			MaxStringLen:       64,
+			MaxArrayValues:     64,
+			MaxStructFields:    -1,
+		}
+	}
+
+	frames, err := c.client.Stacktrace(goroutineID, depth, 0, cfg)
+	if err != nil {
+		return c.createStacktraceResponse(state, goroutineID, nil, fmt.Errorf("failed to get stacktrace of goroutine %d: %v", goroutineID, err))
+	}
+
+	var result []types.StackFrame
+	for i, frame := range frames {
+		result = append(result, convertStackFrame(i, frame))
+	}
+	return c.createStacktraceResponse(state, goroutineID, result, nil)
+}
+
+// allGoroutines pages through every goroutine of the target
+func (c *Client) allGoroutines() ([]*api.Goroutine, error) {
+	var goroutines []*api.Goroutine
+	start := 0
+	for {
+		page, next, err := c.client.ListGoroutines(start, goroutinePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list goroutines: %v", err)
+		}
+		goroutines = append(goroutines, page...)
+		if next < 0 || len(page) == 0 {
+			return goroutines, nil
+		}
+		start = next
+	}
+}
+
+// waitReasons returns the target's runtime.waitReasonStrings, read once per session
+func (c *Client) waitReasons() []string {
+	if c.waitReasonNames != nil {
+		return c.waitReasonNames
+	}
+
+	cfg := api.LoadConfig{MaxStringLen: 64, MaxArrayValues: 256}
+	v, err := c.client.EvalVariable(api.EvalScope{GoroutineID: -1}, "runtime.waitReasonStrings", cfg)
+	if err != nil {
+		logger.Debug("Warning: Failed to read runtime.waitReasonStrings: %v", err)
+		return nil
+	}
+	names := make([]string, 0, len(v.Children))
+	for _, reason := range v.Children {
+		names = append(names, reason.Value)
+	}
+	c.waitReasonNames = names
+	return names
+}
+
+// convertGoroutine describes a goroutine by its user-level location, with
+// reasons from waitReasons to name what it waits for
+func convertGoroutine(g *api.Goroutine, state *api.DebuggerState, reasons []string) types.Goroutine {
+	goroutine := types.Goroutine{
+		ID:             g.ID,
+		Status:         getGoroutineStatus(g),
+		WaitReason:     getWaitReason(g, reasons),
+		Location:       formatLocation(g.UserCurrentLoc),
+		StartLocation:  formatLocation(g.StartLoc),
+		GoStatementLoc: formatLocation(g.GoStatementLoc),
+		ThreadID:       g.ThreadID,
+		Labels:         g.Labels,
+	}
+	if state != nil && state.SelectedGoroutine != nil && state.SelectedGoroutine.ID == g.ID {
+		goroutine.Selected = true
+	}
+	return goroutine
+}
+
+// convertStackFrame describes one frame, with its variables if they were loaded
+func convertStackFrame(index int, frame api.Stackframe) types.StackFrame {
+	result := types.StackFrame{
+		Index:    index,
+		Location: formatFrame(frame),
+		Error:    frame.Err,
+	}
+	if frame.Function != nil {
+		result.Function = frame.Function.Name()
+	}
+	for i := range frame.Arguments {
+		result.Variables = append(result.Variables, convertVariable(&frame.Arguments[i], "argument"))
+	}
+	for i := range frame.Locals {
+		result.Variables = append(result.Variables, convertVariable(&frame.Locals[i], "local"))
+	}
+	return result
+}
+
+// formatLocation formats a location like "main.process at /src/main.go:42"
+func formatLocation(loc api.Location) string {
+	if loc.File == "" && loc.Function == nil {
+		return ""
+	}
+	return formatFrame(api.Stackframe{Location: loc})
+}
+
+// createGoroutinesResponse creates a response for the goroutines command
+func (c *Client) createGoroutinesResponse(state *api.DebuggerState, goroutines []types.Goroutine, err error) types.GoroutinesResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "goroutines"
+
+	response := types.GoroutinesResponse{
+		Status:     "success",
+		Context:    context,
+		Goroutines: goroutines,
+		Total:      len(goroutines),
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	counts := make(map[string]int)
+	for _, g := range goroutines {
+		counts[g.Status]++
+	}
+	response.Summary = fmt.Sprintf("%d goroutines (%d running, %d runnable, %d waiting, %d in syscall)",
+		len(goroutines), counts["running"], counts["runnable"], counts["waiting"], counts["syscall"])
+	return response
+}
+
+// createStacktraceResponse creates a response for the stacktrace command
+func (c *Client) createStacktraceResponse(state *api.DebuggerState, goroutineID int64, frames []types.StackFrame, err error) types.StacktraceResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "stacktrace"
+
+	response := types.StacktraceResponse{
+		Status:      "success",
+		Context:     context,
+		GoroutineID: goroutineID,
+		Frames:      frames,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.Summary = fmt.Sprintf("Goroutine %d, %d frames", goroutineID, len(frames))
+	if len(frames) > 0 {
+		response.Summary += ", innermost " + frames[0].Location
+	}
+	return response
+}
@@ -0,0 +1,71 @@
+package debugger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestConvertGoroutine(t *testing.T) {
+	g := &api.Goroutine{
+		ID:             7,
+		Status:         4, // _Gwaiting
+		WaitReason:     14,
+		UserCurrentLoc: api.Location{File: "/src/worker.go", Line: 30, Function: &api.Function{Name_: "main.worker"}},
+		StartLoc:       api.Location{File: "/src/worker.go", Line: 25, Function: &api.Function{Name_: "main.worker"}},
+	}
+	state := &api.DebuggerState{SelectedGoroutine: &api.Goroutine{ID: 7}}
+
+	reasons := make([]string, 15)
+	reasons[14] = "chan receive"
+	goroutine := convertGoroutine(g, state, reasons)
+	if goroutine.Status != "waiting" || goroutine.WaitReason != "chan receive" {
+		t.Errorf("Expected a goroutine waiting on chan receive, got %+v", goroutine)
+	}
+	if goroutine.Location != "main.worker at /src/worker.go:30" || goroutine.GoStatementLoc != "" {
+		t.Errorf("Unexpected locations: %+v", goroutine)
+	}
+	if !goroutine.Selected {
+		t.Errorf("Expected the selected goroutine to be marked")
+	}
+}
+
+func TestGetWaitReason(t *testing.T) {
+	// runtime.waitReasonStrings as read from a target whose order differs from
+	// older releases: index 20 is time.Sleep's and 22 is sync.Mutex.Lock's
+	reasons := make([]string, 24)
+	reasons[18] = "semacquire"
+	reasons[20] = "sleep"
+	reasons[22] = "sync.Mutex.Lock"
+
+	tests := map[int64]string{
+		0:  "",
+		18: "semacquire",
+		20: "sleep",
+		22: "sync.Mutex.Lock",
+		21: "wait reason 21", // Unnamed in the table
+		40: "wait reason 40", // Past the end of the table
+	}
+	for reason, want := range tests {
+		if got := getWaitReason(&api.Goroutine{WaitReason: reason}, reasons); got != want {
+			t.Errorf("getWaitReason(%d) = %q, want %q", reason, got, want)
+		}
+	}
+	if got := getWaitReason(&api.Goroutine{WaitReason: 22}, nil); got != "wait reason 22" {
+		t.Errorf("Expected a fallback when the table could not be read, got %q", got)
+	}
+}
+
+func TestReadOnlyCoreSession(t *testing.T) {
+	c := NewClient()
+	if err := c.readOnlyError("continue"); err != nil {
+		t.Fatalf("Expected a live session to allow continue, got %v", err)
+	}
+
+	c.coreFile = "/tmp/core.1234"
+	err := c.readOnlyError("continue")
+	if err == nil || !strings.Contains(err.Error(), "continue is not available when inspecting a core dump") {
+		t.Errorf("Expected continue to be refused on a core dump, got %v", err)
+	}
+}
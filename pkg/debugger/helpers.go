@@ -62,63 +62,40 @@ func getGoroutineStatus(g *api.Goroutine) string {
 	if g == nil {
 		return "unknown"
 	}
+	// Based on the runtime's _Gidle, _Grunnable, ... constants
 	switch g.Status {
 	case 0:
-		return "running"
+		return "idle"
 	case 1:
-		return "sleeping"
+		return "runnable"
 	case 2:
-		return "blocked"
+		return "running"
 	case 3:
-		return "waiting"
+		return "syscall"
 	case 4:
+		return "waiting"
+	case 6:
 		return "dead"
+	case 8:
+		return "copystack"
+	case 9:
+		return "preempted"
 	default:
 		return fmt.Sprintf("unknown status %d", g.Status)
 	}
 }
 
-// getWaitReason returns a human-readable wait reason for a goroutine
-func getWaitReason(g *api.Goroutine) string {
+// getWaitReason returns a human-readable wait reason for a goroutine from
+// reasons, the target's runtime.waitReasonStrings. Wait reason values differ
+// between Go releases, so they cannot be decoded with a fixed table.
+func getWaitReason(g *api.Goroutine, reasons []string) string {
 	if g == nil || g.WaitReason == 0 {
 		return ""
 	}
-
-	// Based on runtime/runtime2.go waitReasons
-	switch g.WaitReason {
-	case 1:
-		return "waiting for GC cycle"
-	case 2:
-		return "waiting for GC (write barrier)"
-	case 3:
-		return "waiting for GC (mark assist)"
-	case 4:
-		return "waiting for finalizer"
-	case 5:
-		return "waiting for channel operation"
-	case 6:
-		return "waiting for select operation"
-	case 7:
-		return "waiting for mutex/rwmutex"
-	case 8:
-		return "waiting for concurrent map operation"
-	case 9:
-		return "waiting for garbage collection scan"
-	case 10:
-		return "waiting for channel receive"
-	case 11:
-		return "waiting for channel send"
-	case 12:
-		return "waiting for semaphore"
-	case 13:
-		return "waiting for sleep"
-	case 14:
-		return "waiting for timer"
-	case 15:
-		return "waiting for defer"
-	default:
-		return fmt.Sprintf("unknown wait reason %d", g.WaitReason)
+	if g.WaitReason > 0 && g.WaitReason < int64(len(reasons)) && reasons[g.WaitReason] != "" {
+		return reasons[g.WaitReason]
 	}
+	return fmt.Sprintf("wait reason %d", g.WaitReason)
 }
 
 // getBreakpointStatus returns a human-readable breakpoint status
@@ -0,0 +1,108 @@
+//go:build linux
+
+package debugger
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+)
+
+const (
+	personalityGet  = 0xffffffff
+	addrNoRandomize = 0x0040000
+)
+
+// startStopped starts argv with exactly the environment env, stopped before
+// its first instruction so Delve can attach to it without missing anything.
+// Delve starts the targets it launches with the environment of this process,
+// which all sessions share, so a target with its own environment starts here.
+func startStopped(argv []string, dir string, env []string, disableASLR bool, stdinPath string, stdout, stderr proc.OutputRedirect) (*os.Process, error) {
+	var stdin *os.File
+	if stdinPath != "" {
+		f, err := os.Open(stdinPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open stdin: %v", err)
+		}
+		defer f.Close()
+		stdin = f
+	}
+
+	// Output goes to a file or, as from proc.Redirector on Unix, a FIFO
+	var outputs [2]*os.File
+	for i, redirect := range []proc.OutputRedirect{stdout, stderr} {
+		if redirect.File != nil {
+			outputs[i] = redirect.File
+			continue
+		}
+		// Opened read-write, a FIFO doesn't wait for its reader to open it
+		f, err := os.OpenFile(redirect.Path, os.O_RDWR, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open output redirect: %v", err)
+		}
+		defer f.Close()
+		outputs[i] = f
+	}
+
+	// Only the thread that started a traced process may wait for and detach from it
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if disableASLR {
+		// The personality is inherited by the child, as in Delve's own launch
+		old, _, errno := syscall.RawSyscall(syscall.SYS_PERSONALITY, personalityGet, 0, 0)
+		if errno == 0 {
+			syscall.RawSyscall(syscall.SYS_PERSONALITY, old|addrNoRandomize, 0, 0)
+			defer syscall.RawSyscall(syscall.SYS_PERSONALITY, old, 0, 0)
+		}
+	}
+
+	cmd := exec.Command(argv[0])
+	cmd.Args = argv
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = outputs[0]
+	cmd.Stderr = outputs[1]
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Ptrace: true, Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start program: %v", err)
+	}
+	pid := cmd.Process.Pid
+
+	// The traced program stops with SIGTRAP once exec completes
+	var status syscall.WaitStatus
+	if _, err := syscall.Wait4(pid, &status, syscall.WALL, nil); err != nil {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("failed to wait for program to start: %v", err)
+	}
+	if !status.Stopped() {
+		return nil, fmt.Errorf("program did not start: %v", status)
+	}
+
+	// Queue a SIGSTOP before detaching so the program stays stopped for Delve
+	if err := syscall.Kill(pid, syscall.SIGSTOP); err != nil {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("failed to stop program: %v", err)
+	}
+	if err := syscall.PtraceDetach(pid); err != nil {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("failed to hand program to the debugger: %v", err)
+	}
+
+	// Delve reports the exit as the tracer; this process is still the parent and reaps it
+	go func() {
+		if err := cmd.Wait(); err != nil {
+			logger.Debug("Program %d ended: %v", pid, err)
+		}
+	}()
+
+	return cmd.Process, nil
+}
@@ -0,0 +1,88 @@
+package debugger
+
+import (
+	"io"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/rpc2"
+)
+
+func TestStartStopped(t *testing.T) {
+	// Launches capture output through Delve's redirector, a FIFO on Linux
+	reader, redirect, err := proc.Redirector()
+	if err != nil {
+		t.Fatalf("Failed to create redirector: %v", err)
+	}
+
+	process, err := startStopped([]string{"/bin/sh", "-c", "echo $MCP_STARTED"}, t.TempDir(), []string{"MCP_STARTED=yes"}, true, "", redirect, redirect)
+	if err != nil {
+		t.Fatalf("startStopped failed: %v", err)
+	}
+
+	// Nothing runs until the debugger would resume the program
+	output := make(chan string, 1)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		reader.Close()
+		output <- string(data)
+	}()
+	select {
+	case got := <-output:
+		t.Fatalf("Expected the program to stay stopped, it printed %q", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := syscall.Kill(process.Pid, syscall.SIGCONT); err != nil {
+		t.Fatalf("Failed to resume program: %v", err)
+	}
+	select {
+	case got := <-output:
+		if strings.TrimSpace(got) != "yes" {
+			t.Errorf("Expected the given environment, got %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		process.Kill()
+		t.Fatalf("Program did not finish after being resumed")
+	}
+}
+
+func TestCloseKillsStartedProgram(t *testing.T) {
+	_, redirect, err := proc.Redirector()
+	if err != nil {
+		t.Fatalf("Failed to create redirector: %v", err)
+	}
+	defer os.Remove(redirect.Path)
+
+	process, err := startStopped([]string{"/bin/sleep", "60"}, t.TempDir(), []string{"MCP_STARTED=yes"}, true, "", redirect, redirect)
+	if err != nil {
+		t.Fatalf("startStopped failed: %v", err)
+	}
+	defer process.Kill()
+
+	// A Delve server that is already gone, so detaching kills nothing
+	conn, peer := net.Pipe()
+	peer.Close()
+	c := NewClient()
+	c.client = rpc2.NewClientFromConn(conn)
+	c.started = process
+
+	c.Close(true)
+
+	// The program is reaped once killed
+	deadline := time.Now().Add(5 * time.Second)
+	for syscall.Kill(process.Pid, 0) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected Close to kill the started program")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if c.started != nil {
+		t.Errorf("Expected the started program to be forgotten")
+	}
+}
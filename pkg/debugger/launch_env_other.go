@@ -0,0 +1,18 @@
+//go:build !linux
+
+package debugger
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+// startStopped starts argv with exactly the environment env, stopped before
+// its first instruction. Delve starts the targets it launches with the
+// environment of this process, so this is the only way to give one its own.
+func startStopped(argv []string, dir string, env []string, disableASLR bool, stdinPath string, stdout, stderr proc.OutputRedirect) (*os.Process, error) {
+	return nil, fmt.Errorf("env, unsetEnv and clearEnv are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,133 @@
+package debugger
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/config"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// defaultGcflags disables optimizations so variables remain inspectable
+const defaultGcflags = "-gcflags all=-N"
+
+// goCommand returns a go command that runs in dir
+func goCommand(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// debugBinaryPath returns a new file in the temporary directory to build a
+// debug binary to, so builds never leave binaries in the working directory
+func debugBinaryPath(name string) (string, error) {
+	pattern := name
+	if runtime.GOOS == "windows" {
+		pattern += "*.exe"
+	}
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create debug binary: %v", err)
+	}
+	f.Close()
+	// Builds may run in another directory, so a relative TMPDIR won't do
+	return filepath.Abs(f.Name())
+}
+
+// goTestBuild compiles the tests of the package in dir to debugBinary. The
+// build runs in dir rather than changing the working directory of the server,
+// which concurrent sessions share; go test -c does not accept -C after -c.
+func goTestBuild(dir string, debugBinary string, buildflags string) (string, []byte, error) {
+	args := []string{"test", "-c", "-o", debugBinary}
+	args = append(args, config.SplitQuotedFields(buildflags, '\'')...)
+	args = append(args, ".")
+
+	output, err := goCommand(dir, args...).CombinedOutput()
+	return strings.Join(append([]string{"go"}, args...), " "), output, err
+}
+
+// resolveWorkingDir returns the absolute working directory for the debuggee,
+// defaulting to the current directory
+func resolveWorkingDir(cwd string) (string, error) {
+	if cwd == "" {
+		return os.Getwd()
+	}
+
+	absDir, err := filepath.Abs(cwd)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of working directory: %v", err)
+	}
+
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return "", fmt.Errorf("working directory not found: %s", absDir)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("working directory is not a directory: %s", absDir)
+	}
+
+	return absDir, nil
+}
+
+// launchEnv returns the environment requested by opts, or nil when the
+// debuggee should inherit the environment of this process
+func launchEnv(opts types.LaunchOptions) []string {
+	if !opts.ClearEnv && len(opts.Env) == 0 && len(opts.UnsetEnv) == 0 {
+		return nil
+	}
+
+	env := map[string]string{}
+	if !opts.ClearEnv {
+		for _, kv := range os.Environ() {
+			if key, value, ok := strings.Cut(kv, "="); ok && key != "" {
+				env[key] = value
+			}
+		}
+	}
+	for _, key := range opts.UnsetEnv {
+		delete(env, key)
+	}
+	for key, value := range opts.Env {
+		env[key] = value
+	}
+
+	result := make([]string, 0, len(env))
+	for key, value := range env {
+		result = append(result, key+"="+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// buildFlagsFor assembles the go build flags for a debug build. The result is
+// in the quoted string form understood by gobuild, where user flags come after
+// Delve's own -gcflags so they take precedence.
+func buildFlagsFor(opts types.LaunchOptions) string {
+	flags := []string{defaultGcflags}
+
+	if len(opts.Tags) > 0 {
+		flags = append(flags, quoteBuildFlag("-tags="+strings.Join(opts.Tags, ",")))
+	}
+	if opts.Race {
+		flags = append(flags, "-race")
+	}
+	for _, flag := range opts.BuildFlags {
+		flags = append(flags, quoteBuildFlag(flag))
+	}
+
+	return strings.Join(flags, " ")
+}
+
+// quoteBuildFlag protects a flag containing whitespace from being split by gobuild
+func quoteBuildFlag(flag string) string {
+	if !strings.ContainsAny(flag, " \t'") {
+		return flag
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(flag)
+	return "'" + escaped + "'"
+}
@@ -0,0 +1,97 @@
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/go-delve/delve/pkg/config"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestBuildFlagsFor(t *testing.T) {
+	flags := buildFlagsFor(types.LaunchOptions{
+		Tags:       []string{"integration", "linux"},
+		Race:       true,
+		BuildFlags: []string{"-ldflags=-X main.version=it's 1.0"},
+	})
+
+	// gobuild splits the flags with single quotes, so quoted flags must survive intact
+	got := config.SplitQuotedFields(flags, '\'')
+	expected := []string{"-gcflags", "all=-N", "-tags=integration,linux", "-race", "-ldflags=-X main.version=it's 1.0"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestLaunchEnv(t *testing.T) {
+	t.Setenv("MCP_LAUNCH_KEEP", "keep")
+	t.Setenv("MCP_LAUNCH_UNSET", "unset")
+
+	if env := launchEnv(types.LaunchOptions{}); env != nil {
+		t.Errorf("Expected nil to inherit the environment, got %d variables", len(env))
+	}
+
+	env := launchEnv(types.LaunchOptions{
+		Env:      map[string]string{"MCP_LAUNCH_KEEP": "override", "MCP_LAUNCH_NEW": "new"},
+		UnsetEnv: []string{"MCP_LAUNCH_UNSET"},
+	})
+	if !slices.Contains(env, "MCP_LAUNCH_KEEP=override") || !slices.Contains(env, "MCP_LAUNCH_NEW=new") {
+		t.Errorf("Expected overridden and added values, got %q", env)
+	}
+	if slices.Contains(env, "MCP_LAUNCH_UNSET=unset") {
+		t.Errorf("Expected MCP_LAUNCH_UNSET to be removed")
+	}
+	if !slices.Contains(env, "PATH="+os.Getenv("PATH")) {
+		t.Errorf("Expected the server's environment to be inherited")
+	}
+
+	// The server's own environment is never changed
+	if got := os.Getenv("MCP_LAUNCH_KEEP"); got != "keep" {
+		t.Errorf("Expected the server's value to stay, got %q", got)
+	}
+
+	env = launchEnv(types.LaunchOptions{ClearEnv: true, Env: map[string]string{"ONLY": "1"}})
+	if !reflect.DeepEqual(env, []string{"ONLY=1"}) {
+		t.Errorf("Expected only the given variables, got %q", env)
+	}
+	if env := launchEnv(types.LaunchOptions{ClearEnv: true}); env == nil || len(env) != 0 {
+		t.Errorf("Expected an empty environment, got %q", env)
+	}
+}
+
+func TestGoTestBuildKeepsWorkingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module example.com/sample\n\ngo 1.20\n",
+		"add_test.go": "package sample\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {}\n",
+		"add.go":      "package sample\n\nfunc Add(a, b int) int { return a + b }\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(t.TempDir(), "debug.test")
+	cmd, output, err := goTestBuild(dir, binary, buildFlagsFor(types.LaunchOptions{}))
+	if err != nil {
+		t.Fatalf("Build failed: %v\n%s", err, output)
+	}
+	if !strings.HasPrefix(cmd, "go test -c -o "+binary) {
+		t.Errorf("Unexpected build command %q", cmd)
+	}
+	if _, err := os.Stat(binary); err != nil {
+		t.Errorf("Expected the test binary to be written: %v", err)
+	}
+	if after, _ := os.Getwd(); after != before {
+		t.Errorf("Working directory changed from %s to %s", before, after)
+	}
+}
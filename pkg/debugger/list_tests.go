@@ -0,0 +1,301 @@
+package debugger
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// testFunctionKinds maps test function name prefixes to their kind and the
+// testing type of their single parameter ("" for examples, which take none)
+var testFunctionKinds = []struct {
+	prefix    string
+	kind      string
+	paramType string
+}{
+	{"Test", "test", "T"},
+	{"Benchmark", "benchmark", "B"},
+	{"Fuzz", "fuzz", "F"},
+	{"Example", "example", ""},
+}
+
+// ListTests finds the tests, benchmarks, fuzz targets and examples of a package
+// by parsing its _test.go files. Subtests are reported where their names are
+// string literals, either directly in t.Run or in the table a loop ranges over.
+func ListTests(pkg string, dir string) types.ListTestsResponse {
+	response := types.ListTestsResponse{
+		Package: pkg,
+		Context: types.DebugContext{
+			Timestamp: time.Now(),
+			Operation: "list_tests",
+		},
+	}
+
+	resolved, err := resolvePackage(pkg, dir)
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+	response.ImportPath = resolved.ImportPath
+	response.PackageDir = resolved.Dir
+
+	fset := token.NewFileSet()
+	files := append(append([]string{}, resolved.TestGoFiles...), resolved.XTestGoFiles...)
+	for _, name := range files {
+		path := filepath.Join(resolved.Dir, name)
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			response.Status = "error"
+			response.Context.ErrorMessage = fmt.Sprintf("failed to parse %s: %v", path, err)
+			return response
+		}
+		response.Tests = append(response.Tests, findTestFunctions(fset, file)...)
+	}
+
+	sort.SliceStable(response.Tests, func(i, j int) bool {
+		if response.Tests[i].File != response.Tests[j].File {
+			return response.Tests[i].File < response.Tests[j].File
+		}
+		return response.Tests[i].Line < response.Tests[j].Line
+	})
+
+	counts := map[string]int{}
+	for _, test := range response.Tests {
+		counts[test.Kind]++
+	}
+	response.Status = "success"
+	response.Summary = fmt.Sprintf("Found %d tests, %d benchmarks, %d fuzz targets and %d examples in %s",
+		counts["test"], counts["benchmark"], counts["fuzz"], counts["example"], resolved.ImportPath)
+
+	return response
+}
+
+// findTestFunctions returns the test functions declared in a parsed _test.go file
+func findTestFunctions(fset *token.FileSet, file *ast.File) []types.TestFunction {
+	var tests []types.TestFunction
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Body == nil {
+			continue
+		}
+
+		for _, k := range testFunctionKinds {
+			if !isTestFunctionName(fn.Name.Name, k.prefix) {
+				continue
+			}
+
+			param, ok := testingParam(fn, k.paramType)
+			if !ok {
+				break
+			}
+
+			position := fset.Position(fn.Pos())
+			test := types.TestFunction{
+				Name: fn.Name.Name,
+				Kind: k.kind,
+				File: position.Filename,
+				Line: position.Line,
+			}
+			if param != "" {
+				test.Subtests = findSubtests(fset, fn.Body, param, fn.Name.Name)
+			}
+			tests = append(tests, test)
+			break
+		}
+	}
+
+	return tests
+}
+
+// isTestFunctionName applies the go test naming rule: the prefix must not be
+// followed by a lower-case letter. TestMain is the test entry point, not a test.
+func isTestFunctionName(name string, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) || name == "TestMain" {
+		return false
+	}
+	if len(name) == len(prefix) {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return !unicode.IsLower(r)
+}
+
+// testingParam checks the signature of a test function and returns the name
+// of its *testing.T/B/F parameter. Examples take no parameters and return "".
+func testingParam(fn *ast.FuncDecl, paramType string) (string, bool) {
+	params := fn.Type.Params.List
+	if paramType == "" {
+		return "", len(params) == 0 && fn.Type.Results == nil
+	}
+
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return "", false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return "", false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != paramType {
+		return "", false
+	}
+
+	if len(params[0].Names) == 0 || params[0].Names[0].Name == "_" {
+		return "", true
+	}
+	return params[0].Names[0].Name, true
+}
+
+// findSubtests looks for param.Run(name, func...) calls in body and returns
+// the full names of the subtests whose names can be determined statically
+func findSubtests(fset *token.FileSet, body *ast.BlockStmt, param string, parent string) []types.Subtest {
+	var subtests []types.Subtest
+	literals := map[string]*ast.CompositeLit{} // variable -> table literal assigned to it
+	tables := map[string]map[string][]string{} // range value variable -> field -> literal values
+	keys := map[string][]string{}              // range key variable -> literal map keys
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			// Remember table literals such as tests := []struct{...}{...}
+			for i, lhs := range n.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && i < len(n.Rhs) {
+					if lit, ok := n.Rhs[i].(*ast.CompositeLit); ok {
+						literals[ident.Name] = lit
+					}
+				}
+			}
+		case *ast.RangeStmt:
+			lit, ok := n.X.(*ast.CompositeLit)
+			if ident, isIdent := n.X.(*ast.Ident); isIdent {
+				lit, ok = literals[ident.Name]
+			}
+			if !ok {
+				return true
+			}
+			if key, ok := n.Key.(*ast.Ident); ok {
+				keys[key.Name] = tableKeys(lit)
+			}
+			if value, ok := n.Value.(*ast.Ident); ok {
+				tables[value.Name] = tableFieldValues(lit)
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Run" || len(n.Args) != 2 {
+				return true
+			}
+			if recv, ok := sel.X.(*ast.Ident); !ok || recv.Name != param {
+				return true
+			}
+
+			for _, name := range subtestNames(n.Args[0], tables, keys) {
+				fullName := parent + "/" + rewriteSubtestName(name)
+				subtests = append(subtests, types.Subtest{
+					Name: fullName,
+					Line: fset.Position(n.Pos()).Line,
+				})
+
+				// Nested subtests use the parameter of the function literal
+				if fnLit, ok := n.Args[1].(*ast.FuncLit); ok {
+					params := fnLit.Type.Params.List
+					if len(params) == 1 && len(params[0].Names) == 1 {
+						subtests = append(subtests, findSubtests(fset, fnLit.Body, params[0].Names[0].Name, fullName)...)
+					}
+				}
+			}
+			// The function literal was already searched under the right parent
+			return false
+		}
+		return true
+	})
+
+	return subtests
+}
+
+// subtestNames returns the statically known names passed as a subtest name:
+// a string literal, a map key ranged over, or a field of the table case ranged over
+func subtestNames(expr ast.Expr, tables map[string]map[string][]string, keys map[string][]string) []string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if name, ok := stringLiteral(e); ok {
+			return []string{name}
+		}
+	case *ast.Ident:
+		return keys[e.Name]
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok {
+			return tables[ident.Name][e.Sel.Name]
+		}
+	}
+	return nil
+}
+
+// tableKeys returns the string literal keys of a map literal
+func tableKeys(lit *ast.CompositeLit) []string {
+	var names []string
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.BasicLit); ok {
+				if name, ok := stringLiteral(key); ok {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// tableFieldValues collects, per field, the string literal values of the keyed
+// struct literals in a slice or map literal
+func tableFieldValues(lit *ast.CompositeLit) map[string][]string {
+	fields := map[string][]string{}
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = kv.Value
+		}
+		if unary, ok := elt.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			elt = unary.X
+		}
+		caseLit, ok := elt.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		for _, field := range caseLit.Elts {
+			kv, ok := field.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if value, ok := kv.Value.(*ast.BasicLit); ok {
+				if name, ok := stringLiteral(value); ok {
+					fields[key.Name] = append(fields[key.Name], name)
+				}
+			}
+		}
+	}
+	return fields
+}
+
+// stringLiteral unquotes a string literal
+func stringLiteral(lit *ast.BasicLit) (string, bool) {
+	if lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
@@ -0,0 +1,107 @@
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListTests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/listtests\n\ngo 1.21\n",
+		"shapes.go": `package shapes
+
+func Area(w, h int) int { return w * h }
+`,
+		"shapes_test.go": `package shapes
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMain(m *testing.M) { m.Run() }
+
+func TestArea(t *testing.T) {
+	t.Run("square", func(t *testing.T) {
+		t.Run("unit side", func(t *testing.T) {})
+	})
+
+	cases := []struct {
+		name string
+		w, h int
+	}{
+		{name: "wide", w: 2, h: 1},
+		{name: "tall", w: 1, h: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {})
+	}
+
+	for name := range map[string]int{"zero": 0} {
+		t.Run(name, func(t *testing.T) {})
+	}
+
+	t.Run(fmt.Sprint("dynamic"), func(t *testing.T) {})
+}
+
+func Testhelper(t *testing.T) {}
+
+func BenchmarkArea(b *testing.B) {
+	b.Run("small", func(b *testing.B) {})
+}
+
+func FuzzArea(f *testing.F) {}
+`,
+		"example_test.go": `package shapes_test
+
+import "fmt"
+
+func ExampleArea() {
+	fmt.Println(2)
+	// Output: 2
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	response := ListTests(".", dir)
+	if response.Status != "success" {
+		t.Fatalf("Expected success, got %s: %s", response.Status, response.Context.ErrorMessage)
+	}
+
+	type found struct {
+		Name     string
+		Kind     string
+		Subtests []string
+	}
+	var got []found
+	for _, test := range response.Tests {
+		f := found{Name: test.Name, Kind: test.Kind}
+		for _, sub := range test.Subtests {
+			f.Subtests = append(f.Subtests, sub.Name)
+		}
+		got = append(got, f)
+		if test.Line == 0 || filepath.Dir(test.File) != dir {
+			t.Errorf("Expected a position in %s, got %s:%d", dir, test.File, test.Line)
+		}
+	}
+
+	expected := []found{
+		{Name: "ExampleArea", Kind: "example"},
+		{Name: "TestArea", Kind: "test", Subtests: []string{
+			"TestArea/square", "TestArea/square/unit_side", "TestArea/wide", "TestArea/tall", "TestArea/zero",
+		}},
+		{Name: "BenchmarkArea", Kind: "benchmark", Subtests: []string{"BenchmarkArea/small"}},
+		{Name: "FuzzArea", Kind: "fuzz"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, got)
+	}
+}
@@ -0,0 +1,295 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Bits of sync.Mutex.state, as defined by the sync package
+const (
+	mutexLocked      = 1
+	mutexWoken       = 2
+	mutexStarving    = 4
+	mutexWaiterShift = 3
+
+	// rwmutexMaxReaders is subtracted from readerCount while a writer is pending
+	rwmutexMaxReaders = 1 << 30
+)
+
+// InspectMutex decodes the state of the mutex that expr evaluates to, or of
+// every mutex a blocked goroutine waits on when expr is empty, and lists the
+// goroutines waiting for it. Mutexes do not record their owner, so goroutines
+// whose own frames refer to the mutex are reported as possible holders.
+func (c *Client) InspectMutex(expr string) types.InspectMutexResponse {
+	if c.client == nil {
+		return c.createInspectMutexResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return c.createInspectMutexResponse(nil, nil, c.exitedError())
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createInspectMutexResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+	if state.SelectedGoroutine == nil {
+		return c.createInspectMutexResponse(state, nil, fmt.Errorf("no goroutine selected"))
+	}
+	scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID}
+
+	goroutines, err := c.allGoroutines()
+	if err != nil {
+		return c.createInspectMutexResponse(state, nil, err)
+	}
+	reasons := c.waitReasons()
+	var inspected []*blockedGoroutine
+	for _, g := range goroutines {
+		if isRuntimeGoroutine(g) {
+			continue
+		}
+		if len(inspected) == maxAnalyzedStacks {
+			logger.Debug("Only inspecting the stacks of the first %d goroutines", maxAnalyzedStacks)
+			break
+		}
+		b := &blockedGoroutine{goroutine: convertGoroutine(g, state, reasons)}
+		c.inspectBlocked(b)
+		inspected = append(inspected, b)
+	}
+
+	var targets []mutexTarget
+	if expr != "" {
+		target, err := c.resolveMutex(scope, expr)
+		if err != nil {
+			return c.createInspectMutexResponse(state, nil, err)
+		}
+		targets = append(targets, target)
+	} else {
+		targets = waitedMutexes(inspected)
+		if len(targets) == 0 {
+			return c.createInspectMutexResponse(state, nil, fmt.Errorf("no goroutine is blocked on a mutex; pass an expression to inspect one"))
+		}
+	}
+
+	var mutexes []types.MutexState
+	for _, target := range targets {
+		mutex, err := c.readMutex(scope, target)
+		if err != nil {
+			return c.createInspectMutexResponse(state, nil, err)
+		}
+		addMutexGoroutines(&mutex, inspected)
+		mutexes = append(mutexes, mutex)
+	}
+
+	return c.createInspectMutexResponse(state, mutexes, nil)
+}
+
+// mutexTarget is a sync.Mutex or sync.RWMutex in the target's memory
+type mutexTarget struct {
+	addr uint64
+	kind string // mutex or rwmutex
+}
+
+// resolveMutex evaluates expr to a mutex or a pointer to one
+func (c *Client) resolveMutex(scope api.EvalScope, expr string) (mutexTarget, error) {
+	v, err := c.client.EvalVariable(scope, expr, api.LoadConfig{})
+	if err != nil {
+		return mutexTarget{}, fmt.Errorf("failed to evaluate %s: %v", expr, err)
+	}
+
+	target := mutexTarget{addr: v.Addr}
+	typeName := v.Type
+	if strings.HasPrefix(typeName, "*") {
+		target.addr = pointerTarget(v)
+		typeName = strings.TrimPrefix(typeName, "*")
+	}
+	switch typeName {
+	case "sync.Mutex":
+		target.kind = "mutex"
+	case "sync.RWMutex":
+		target.kind = "rwmutex"
+	default:
+		return mutexTarget{}, fmt.Errorf("%s is a %s, not a sync.Mutex or sync.RWMutex", expr, v.Type)
+	}
+	if target.addr == 0 {
+		return mutexTarget{}, fmt.Errorf("%s is nil", expr)
+	}
+	return target, nil
+}
+
+// waitedMutexes returns the mutexes blocked goroutines wait on. Waiting in
+// RWMutex.Lock can block on its inner mutex, which shares its address, so an
+// address any goroutine waits on as an rwmutex is treated as one.
+func waitedMutexes(inspected []*blockedGoroutine) []mutexTarget {
+	kinds := make(map[uint64]string)
+	var addrs []uint64
+	for _, b := range inspected {
+		kind, addr := parseResource(b.resource)
+		if kind != "mutex" && kind != "rwmutex" {
+			continue
+		}
+		if _, ok := kinds[addr]; !ok {
+			addrs = append(addrs, addr)
+		}
+		if kinds[addr] != "rwmutex" {
+			kinds[addr] = kind
+		}
+	}
+
+	targets := make([]mutexTarget, 0, len(addrs))
+	for _, addr := range addrs {
+		targets = append(targets, mutexTarget{addr: addr, kind: kinds[addr]})
+	}
+	return targets
+}
+
+// parseResource splits a resource like "mutex 0xc000012345" into its kind and address
+func parseResource(resource string) (string, uint64) {
+	kind, addr, ok := strings.Cut(resource, " ")
+	if !ok {
+		return "", 0
+	}
+	value, err := strconv.ParseUint(strings.TrimPrefix(addr, "0x"), 16, 64)
+	if err != nil {
+		return "", 0
+	}
+	return kind, value
+}
+
+// readMutex reads and decodes the state of a mutex
+func (c *Client) readMutex(scope api.EvalScope, target mutexTarget) (types.MutexState, error) {
+	mutex := types.MutexState{Address: fmt.Sprintf("%#x", target.addr), Kind: target.kind}
+
+	// The write lock of an RWMutex is its first field, so it shares its address
+	base := fmt.Sprintf("(*sync.Mutex)(%#x)", target.addr)
+	state, err := c.evalInt(scope, base+".state")
+	if err != nil {
+		// Since Go 1.24 sync.Mutex wraps internal/sync.Mutex
+		state, err = c.evalInt(scope, base+".mu.state")
+	}
+	if err != nil {
+		return mutex, err
+	}
+	decodeMutexState(state, &mutex)
+
+	if target.kind == "rwmutex" {
+		rw := fmt.Sprintf("(*sync.RWMutex)(%#x)", target.addr)
+		readerCount, err := c.evalInt(scope, rw+".readerCount.v")
+		if err != nil {
+			return mutex, err
+		}
+		readerWait, err := c.evalInt(scope, rw+".readerWait.v")
+		if err != nil {
+			return mutex, err
+		}
+		decodeRWMutexState(readerCount, readerWait, &mutex)
+	}
+	return mutex, nil
+}
+
+// decodeMutexState decodes the bits of sync.Mutex.state
+func decodeMutexState(state int64, mutex *types.MutexState) {
+	mutex.Locked = state&mutexLocked != 0
+	mutex.Woken = state&mutexWoken != 0
+	mutex.Starving = state&mutexStarving != 0
+	mutex.WaiterCount = int(state >> mutexWaiterShift)
+}
+
+// decodeRWMutexState decodes the reader counts of a sync.RWMutex. A pending
+// writer makes readerCount negative and waits for readerWait readers to leave.
+func decodeRWMutexState(readerCount, readerWait int64, mutex *types.MutexState) {
+	if readerCount < 0 {
+		mutex.WriterPending = true
+		mutex.Readers = int(readerWait)
+		mutex.ReadersWaiting = int(readerCount + rwmutexMaxReaders - readerWait)
+		return
+	}
+	mutex.Readers = int(readerCount)
+}
+
+// addMutexGoroutines adds the goroutines waiting on a mutex and those whose
+// frames refer to it without waiting, which may hold it
+func addMutexGoroutines(mutex *types.MutexState, inspected []*blockedGoroutine) {
+	for _, b := range inspected {
+		if _, addr := parseResource(b.resource); fmt.Sprintf("%#x", addr) == mutex.Address {
+			mutex.Waiting = append(mutex.Waiting, blockedInfo(b, 0))
+			continue
+		}
+		if containsString(b.references, mutex.Address) {
+			holder := blockedInfo(b, 0)
+			holder.Stack = b.stack
+			mutex.PossibleHolders = append(mutex.PossibleHolders, holder)
+		}
+	}
+	sort.Slice(mutex.Waiting, func(i, j int) bool { return mutex.Waiting[i].ID < mutex.Waiting[j].ID })
+}
+
+// evalInt evaluates an expression with a signed integer value
+func (c *Client) evalInt(scope api.EvalScope, expr string) (int64, error) {
+	v, err := c.client.EvalVariable(scope, expr, api.LoadConfig{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to evaluate %s: %v", expr, err)
+	}
+	value, err := strconv.ParseInt(v.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected value %q for %s", v.Value, expr)
+	}
+	return value, nil
+}
+
+// describeMutex describes a mutex like "mutex 0xc000012345: locked, starving, 3 waiters"
+func describeMutex(mutex types.MutexState) string {
+	var state []string
+	if mutex.Locked {
+		state = append(state, "locked")
+	} else {
+		state = append(state, "unlocked")
+	}
+	if mutex.Starving {
+		state = append(state, "starving")
+	}
+	if mutex.Readers > 0 {
+		state = append(state, fmt.Sprintf("%d %s", mutex.Readers, plural(mutex.Readers, "reader", "readers")))
+	}
+	if mutex.WriterPending {
+		state = append(state, "writer pending")
+	}
+	if n := len(mutex.Waiting); n > 0 {
+		state = append(state, fmt.Sprintf("%d %s waiting", n, plural(n, "goroutine", "goroutines")))
+	}
+	description := fmt.Sprintf("%s %s: %s", mutex.Kind, mutex.Address, strings.Join(state, ", "))
+	if len(mutex.PossibleHolders) > 0 {
+		holder := mutex.PossibleHolders[0]
+		description += fmt.Sprintf("; possibly held by goroutine %d at %s", holder.ID, holder.Location)
+	}
+	return description
+}
+
+// createInspectMutexResponse creates a response for the inspect mutex command
+func (c *Client) createInspectMutexResponse(state *api.DebuggerState, mutexes []types.MutexState, err error) types.InspectMutexResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "inspect_mutex"
+
+	response := types.InspectMutexResponse{
+		Status:  "success",
+		Context: context,
+		Mutexes: mutexes,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	descriptions := make([]string, 0, len(mutexes))
+	for _, mutex := range mutexes {
+		descriptions = append(descriptions, describeMutex(mutex))
+	}
+	response.Summary = strings.Join(descriptions, "\n")
+	return response
+}
@@ -0,0 +1,64 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestDecodeMutexState(t *testing.T) {
+	var mutex types.MutexState
+	// Locked and starving with three waiters
+	decodeMutexState(mutexLocked|mutexStarving|3<<mutexWaiterShift, &mutex)
+	if !mutex.Locked || !mutex.Starving || mutex.Woken || mutex.WaiterCount != 3 {
+		t.Errorf("Unexpected decoded state %+v", mutex)
+	}
+
+	var rw types.MutexState
+	// Two readers still hold the lock a writer waits for, and one new reader queued behind it
+	decodeRWMutexState(3-rwmutexMaxReaders, 2, &rw)
+	if !rw.WriterPending || rw.Readers != 2 || rw.ReadersWaiting != 1 {
+		t.Errorf("Unexpected decoded RWMutex state %+v", rw)
+	}
+}
+
+func TestWaitedMutexes(t *testing.T) {
+	inspected := []*blockedGoroutine{
+		{resource: "mutex 0xc0000a0"},
+		{resource: "chan 0xc0000b0"},
+		{resource: "mutex 0xc0000c0"},
+		{resource: "rwmutex 0xc0000c0"},
+		{resource: ""},
+	}
+	targets := waitedMutexes(inspected)
+	if len(targets) != 2 {
+		t.Fatalf("Expected two mutexes, got %+v", targets)
+	}
+	if targets[0].addr != 0xc0000a0 || targets[0].kind != "mutex" {
+		t.Errorf("Unexpected first mutex %+v", targets[0])
+	}
+	if targets[1].addr != 0xc0000c0 || targets[1].kind != "rwmutex" {
+		t.Errorf("Expected the shared address to be an rwmutex, got %+v", targets[1])
+	}
+}
+
+func TestAddMutexGoroutines(t *testing.T) {
+	mutex := types.MutexState{Address: "0xc0000a0", Kind: "mutex", Locked: true}
+	inspected := []*blockedGoroutine{
+		{goroutine: types.Goroutine{ID: 3}, resource: "mutex 0xc0000a0", references: []string{"0xc0000a0"}},
+		{goroutine: types.Goroutine{ID: 1, Location: "main.update at /src/main.go:20"}, references: []string{"0xc0000a0"}},
+		{goroutine: types.Goroutine{ID: 2}, resource: "mutex 0xc0000a0"},
+		{goroutine: types.Goroutine{ID: 4}, references: []string{"0xc0000f0"}},
+	}
+	addMutexGoroutines(&mutex, inspected)
+	if len(mutex.Waiting) != 2 || mutex.Waiting[0].ID != 2 || mutex.Waiting[1].ID != 3 {
+		t.Errorf("Expected goroutines 2 and 3 waiting, got %+v", mutex.Waiting)
+	}
+	if len(mutex.PossibleHolders) != 1 || mutex.PossibleHolders[0].ID != 1 {
+		t.Errorf("Expected goroutine 1 as possible holder, got %+v", mutex.PossibleHolders)
+	}
+	want := "mutex 0xc0000a0: locked, 2 goroutines waiting; possibly held by goroutine 1 at main.update at /src/main.go:20"
+	if got := describeMutex(mutex); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -2,6 +2,7 @@ package debugger
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -10,13 +11,41 @@ import (
 
 // OutputMessage represents a captured output message
 type OutputMessage struct {
+	Seq       int       `json:"seq"`    // Line number in capture order
 	Source    string    `json:"source"` // "stdout" or "stderr"
 	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
-// GetDebuggerOutput returns the captured stdout and stderr from the debugged program
-func (c *Client) GetDebuggerOutput() types.DebuggerOutputResponse {
+// SetOutputHandler registers a function receiving program output lines as they
+// are captured. It is called from a single goroutine, in capture order.
+func (c *Client) SetOutputHandler(handler func(OutputMessage)) {
+	c.outputMutex.Lock()
+	defer c.outputMutex.Unlock()
+	c.outputHandler = handler
+}
+
+// forwardOutput drains the output channel, passing each line to the output handler
+func (c *Client) forwardOutput() {
+	for {
+		select {
+		case <-c.stopOutput:
+			return
+		case msg := <-c.outputChan:
+			c.outputMutex.Lock()
+			handler := c.outputHandler
+			c.outputMutex.Unlock()
+
+			if handler != nil {
+				handler(msg)
+			}
+		}
+	}
+}
+
+// GetDebuggerOutput returns the captured stdout and stderr from the debugged program.
+// The query selects lines by cursor, stream and pattern; the zero query returns everything held.
+func (c *Client) GetDebuggerOutput(query types.OutputQuery) types.DebuggerOutputResponse {
 	if c.client == nil {
 		return types.DebuggerOutputResponse{
 			Status: "error",
@@ -28,46 +57,151 @@ func (c *Client) GetDebuggerOutput() types.DebuggerOutputResponse {
 		}
 	}
 
+	if err := validateLogQuery(query); err != nil {
+		return types.DebuggerOutputResponse{
+			Status: "error",
+			Context: types.DebugContext{
+				Timestamp:    time.Now(),
+				Operation:    "get_output",
+				ErrorMessage: err.Error(),
+			},
+		}
+	}
+
+	var filter *regexp.Regexp
+	if query.Filter != "" {
+		var err error
+		if filter, err = regexp.Compile(query.Filter); err != nil {
+			return types.DebuggerOutputResponse{
+				Status: "error",
+				Context: types.DebugContext{
+					Timestamp:    time.Now(),
+					Operation:    "get_output",
+					ErrorMessage: fmt.Sprintf("invalid filter pattern: %v", err),
+				},
+			}
+		}
+	}
+
 	// Get the captured output regardless of state
 	c.outputMutex.Lock()
-	stdout := c.stdout.String()
-	stderr := c.stderr.String()
+	since := 0
+	if query.SinceLastRead {
+		since = c.readCursor
+	} else if query.Since != nil {
+		since = *query.Since
+	}
+	response := types.DebuggerOutputResponse{
+		Status:     "success",
+		NextCursor: c.output.nextSeq,
+		Dropped:    c.output.dropped(),
+		Unstreamed: c.droppedOutput,
+	}
+	if since < c.output.firstSeq() {
+		response.Missed = c.output.firstSeq() - since
+	}
+	var lines []OutputMessage
+	if query.Format == "" {
+		lines = selectOutput(c.output.since(since), query.Source, filter, query.Tail)
+	} else {
+		lines = selectOutput(c.output.since(since), query.Source, filter, 0)
+	}
+	c.readCursor = c.output.nextSeq
 	c.outputMutex.Unlock()
 
+	// Structured filters apply before tail, so tail counts matching records
+	if query.Format != "" {
+		records := filterLogRecords(parseLogLines(lines, query.Format), query.Level, query.Attrs)
+		if query.Tail > 0 && len(records) > query.Tail {
+			records = records[len(records)-query.Tail:]
+		}
+		lines = recordLines(lines, records)
+		response.Records = records
+	}
+
+	var stdout, stderr strings.Builder
+	for _, line := range lines {
+		if line.Source == "stderr" {
+			stderr.WriteString(line.Content + "\n")
+		} else {
+			stdout.WriteString(line.Content + "\n")
+		}
+	}
+	response.Stdout = stdout.String()
+	response.Stderr = stderr.String()
+	response.Lines = len(lines)
+
 	// Create a summary of the output for LLM
-	outputSummary := generateOutputSummary(stdout, stderr)
+	response.OutputSummary = generateOutputSummary(response.Stdout, response.Stderr, response.Records)
+
+	// An exited program has no state left, but its output stays readable
+	if c.exited {
+		response.Context = c.createDebugContext(nil)
+		response.Context.Operation = "get_output"
+		return response
+	}
 
 	// Try to get state, but don't fail if unable
 	state, err := c.client.GetState()
 	if err != nil {
 		// Process might have exited, but we still want to return the captured output
-		return types.DebuggerOutputResponse{
-			Status: "success",
-			Context: types.DebugContext{
-				Timestamp:    time.Now(),
-				Operation:    "get_output",
-				ErrorMessage: fmt.Sprintf("state unavailable: %v", err),
-			},
-			Stdout:        stdout,
-			Stderr:        stderr,
-			OutputSummary: outputSummary,
+		response.Context = types.DebugContext{
+			Timestamp:    time.Now(),
+			Operation:    "get_output",
+			ErrorMessage: fmt.Sprintf("state unavailable: %v", err),
 		}
+		return response
 	}
 
-	context := c.createDebugContext(state)
-	context.Operation = "get_output"
+	response.Context = c.createDebugContext(state)
+	response.Context.Operation = "get_output"
 
-	return types.DebuggerOutputResponse{
-		Status:        "success",
-		Context:       context,
-		Stdout:        stdout,
-		Stderr:        stderr,
-		OutputSummary: outputSummary,
+	return response
+}
+
+// selectOutput keeps the lines of the given stream ("" for both) that match
+// filter, then the last tail of them (0 for all)
+func selectOutput(lines []OutputMessage, source string, filter *regexp.Regexp, tail int) []OutputMessage {
+	var selected []OutputMessage
+	for _, line := range lines {
+		if source != "" && line.Source != source {
+			continue
+		}
+		if filter != nil && !filter.MatchString(line.Content) {
+			continue
+		}
+		selected = append(selected, line)
 	}
+
+	if tail > 0 && len(selected) > tail {
+		selected = selected[len(selected)-tail:]
+	}
+	return selected
 }
 
-// generateOutputSummary creates a concise summary of stdout and stderr for LLM use
-func generateOutputSummary(stdout, stderr string) string {
+// recordLines returns the lines the records were parsed from
+func recordLines(lines []OutputMessage, records []types.LogRecord) []OutputMessage {
+	kept := make(map[int]bool, len(records))
+	for _, record := range records {
+		kept[record.LineNumber] = true
+	}
+
+	var selected []OutputMessage
+	for _, line := range lines {
+		if kept[line.Seq] {
+			selected = append(selected, line)
+		}
+	}
+	return selected
+}
+
+// generateOutputSummary creates a concise summary of stdout and stderr for LLM use.
+// Parsed log records are summarized by level and message instead.
+func generateOutputSummary(stdout, stderr string, records []types.LogRecord) string {
+	if len(records) > 0 {
+		return generateLogSummary(records)
+	}
+
 	// If no output, return a simple message
 	if stdout == "" && stderr == "" {
 		return "No program output captured"
@@ -0,0 +1,230 @@
+package debugger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// outputBreak halts the debuggee when a captured output line matches pattern
+type outputBreak struct {
+	id       int
+	pattern  *regexp.Regexp
+	source   string // "stdout", "stderr" or "" for both
+	hitCount int
+}
+
+// outputHit is a matched output line waiting to be reported
+type outputHit struct {
+	breakID int
+	pattern string
+	line    OutputMessage
+}
+
+// writeFunctions identify goroutines that are writing to a file or pipe
+var writeFunctions = []string{"syscall.write", "syscall.Write", "internal/poll.(*FD).Write", "os.(*File).Write"}
+
+// BreakOnOutput registers a regular expression; when the program prints a
+// matching line, a running program is halted and the line is reported
+func (c *Client) BreakOnOutput(pattern string, source string) types.OutputBreakpointResponse {
+	if c.client == nil {
+		return c.createOutputBreakpointResponse(nil, fmt.Errorf("no active debug session"))
+	}
+	if err := c.readOnlyError("break_on_output"); err != nil {
+		return c.createOutputBreakpointResponse(nil, err)
+	}
+	if source != "" && source != "stdout" && source != "stderr" {
+		return c.createOutputBreakpointResponse(nil, fmt.Errorf("source must be stdout or stderr, got %q", source))
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return c.createOutputBreakpointResponse(nil, fmt.Errorf("invalid output pattern: %v", err))
+	}
+
+	c.outputMutex.Lock()
+	c.nextOutputBreakID++
+	brk := &outputBreak{id: c.nextOutputBreakID, pattern: re, source: source}
+	c.outputBreaks = append(c.outputBreaks, brk)
+	c.outputMutex.Unlock()
+
+	logger.Debug("Breaking on output matching %s", pattern)
+	created := outputBreakpointInfo(brk)
+	return c.createOutputBreakpointResponse(&created, nil)
+}
+
+// ClearOutputBreak removes an output breakpoint by ID, or all of them for ID 0
+func (c *Client) ClearOutputBreak(id int) types.OutputBreakpointResponse {
+	if c.client == nil {
+		return c.createOutputBreakpointResponse(nil, fmt.Errorf("no active debug session"))
+	}
+
+	c.outputMutex.Lock()
+	var kept []*outputBreak
+	var removed *outputBreak
+	for _, brk := range c.outputBreaks {
+		if id == 0 || brk.id == id {
+			removed = brk
+			continue
+		}
+		kept = append(kept, brk)
+	}
+	c.outputBreaks = kept
+	c.outputMutex.Unlock()
+
+	if removed == nil && id != 0 {
+		return c.createOutputBreakpointResponse(nil, fmt.Errorf("no output breakpoint with ID %d", id))
+	}
+	if id == 0 {
+		return c.createOutputBreakpointResponse(nil, nil)
+	}
+	info := outputBreakpointInfo(removed)
+	return c.createOutputBreakpointResponse(&info, nil)
+}
+
+// matchOutputBreaks checks a captured line against the output breakpoints and
+// halts the program on the first match. Callers must hold outputMutex, which
+// also guards c.client against Close while output is still being captured.
+func (c *Client) matchOutputBreaks(line OutputMessage) {
+	if c.pendingOutputHit != nil {
+		return
+	}
+
+	for _, brk := range c.outputBreaks {
+		if brk.source != "" && brk.source != line.Source {
+			continue
+		}
+		if !brk.pattern.MatchString(line.Content) {
+			continue
+		}
+
+		brk.hitCount++
+		c.pendingOutputHit = &outputHit{breakID: brk.id, pattern: brk.pattern.String(), line: line}
+
+		// A stopped program is not halted again; the hit is reported by the next continue
+		client := c.client
+		if client != nil && c.continuing.Load() {
+			logger.Debug("Output line %d matched %s, halting", line.Seq, brk.pattern)
+			go func() {
+				if _, err := client.Halt(); err != nil {
+					logger.Debug("Warning: Failed to halt on output: %v", err)
+				}
+			}()
+		}
+		return
+	}
+}
+
+// takeOutputHit returns and clears the pending output breakpoint hit
+func (c *Client) takeOutputHit() *outputHit {
+	c.outputMutex.Lock()
+	defer c.outputMutex.Unlock()
+
+	hit := c.pendingOutputHit
+	c.pendingOutputHit = nil
+	return hit
+}
+
+// describeOutputHit reports a matched line with the goroutines that are
+// writing output at the time the program stopped
+func (c *Client) describeOutputHit(hit *outputHit) *types.OutputBreakHit {
+	result := &types.OutputBreakHit{
+		ID:         hit.breakID,
+		Pattern:    hit.pattern,
+		Source:     hit.line.Source,
+		Line:       hit.line.Content,
+		LineNumber: hit.line.Seq,
+		Timestamp:  hit.line.Timestamp,
+	}
+
+	// The write usually completed before the halt landed, so this is best-effort
+	goroutines, _, err := c.client.ListGoroutines(0, 1000)
+	if err != nil {
+		logger.Debug("Warning: Failed to list goroutines: %v", err)
+		return result
+	}
+	for _, g := range goroutines {
+		frames, err := c.client.Stacktrace(g.ID, 30, 0, nil)
+		if err != nil || !isWriting(frames) {
+			continue
+		}
+
+		stack := types.GoroutineStack{ID: g.ID}
+		for _, frame := range frames {
+			stack.Frames = append(stack.Frames, formatFrame(frame))
+		}
+		result.WritingGoroutines = append(result.WritingGoroutines, stack)
+	}
+
+	return result
+}
+
+// isWriting reports whether a stack is inside a write to a file descriptor
+func isWriting(frames []api.Stackframe) bool {
+	for _, frame := range frames {
+		if frame.Function == nil {
+			continue
+		}
+		for _, name := range writeFunctions {
+			if frame.Function.Name() == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatFrame formats a stack frame like "main.process at /src/main.go:42"
+func formatFrame(frame api.Stackframe) string {
+	name := "?"
+	if frame.Function != nil {
+		name = frame.Function.Name()
+	}
+	return fmt.Sprintf("%s at %s:%d", name, frame.File, frame.Line)
+}
+
+// outputBreakpointInfo converts an output breakpoint for responses
+func outputBreakpointInfo(brk *outputBreak) types.OutputBreakpoint {
+	return types.OutputBreakpoint{
+		ID:       brk.id,
+		Pattern:  brk.pattern.String(),
+		Source:   brk.source,
+		HitCount: brk.hitCount,
+	}
+}
+
+// createOutputBreakpointResponse creates a response listing the output breakpoints
+func (c *Client) createOutputBreakpointResponse(brk *types.OutputBreakpoint, err error) types.OutputBreakpointResponse {
+	response := types.OutputBreakpointResponse{
+		Status: "success",
+		Context: types.DebugContext{
+			Timestamp: time.Now(),
+			Operation: "break_on_output",
+		},
+		OutputBreakpoint: brk,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	c.outputMutex.Lock()
+	var patterns []string
+	for _, b := range c.outputBreaks {
+		response.OutputBreakpoints = append(response.OutputBreakpoints, outputBreakpointInfo(b))
+		patterns = append(patterns, b.pattern.String())
+	}
+	c.outputMutex.Unlock()
+
+	response.Summary = "No output breakpoints"
+	if len(patterns) > 0 {
+		response.Summary = fmt.Sprintf("Breaking on output matching %s", strings.Join(patterns, ", "))
+	}
+	return response
+}
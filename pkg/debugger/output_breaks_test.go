@@ -0,0 +1,52 @@
+package debugger
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestMatchOutputBreaks(t *testing.T) {
+	c := NewClient()
+	c.outputBreaks = []*outputBreak{
+		{id: 1, pattern: regexp.MustCompile(`^panic:`), source: "stderr"},
+		{id: 2, pattern: regexp.MustCompile(`ready on :\d+`)},
+	}
+
+	c.matchOutputBreaks(c.output.append("stdout", "panic: only on stdout", time.Now()))
+	if hit := c.takeOutputHit(); hit != nil {
+		t.Fatalf("Expected the stderr-only pattern to skip stdout, got %+v", hit)
+	}
+
+	c.matchOutputBreaks(c.output.append("stdout", "server ready on :8080", time.Now()))
+	c.matchOutputBreaks(c.output.append("stderr", "panic: boom", time.Now()))
+	hit := c.takeOutputHit()
+	if hit == nil || hit.breakID != 2 || hit.line.Seq != 1 {
+		t.Fatalf("Expected the first matching line to be kept, got %+v", hit)
+	}
+	if c.outputBreaks[1].hitCount != 1 || c.outputBreaks[0].hitCount != 0 {
+		t.Errorf("Expected only the reported match to be counted, got %d and %d",
+			c.outputBreaks[0].hitCount, c.outputBreaks[1].hitCount)
+	}
+	if c.takeOutputHit() != nil {
+		t.Errorf("Expected the hit to be cleared once taken")
+	}
+}
+
+func TestIsWriting(t *testing.T) {
+	frame := func(name string) api.Stackframe {
+		return api.Stackframe{Location: api.Location{Function: &api.Function{Name_: name}}}
+	}
+
+	writing := []api.Stackframe{frame("syscall.write"), frame("os.(*File).Write"), frame("fmt.Println"), frame("main.main")}
+	if !isWriting(writing) {
+		t.Errorf("Expected a stack through syscall.write to be writing")
+	}
+
+	idle := []api.Stackframe{frame("runtime.gopark"), frame("main.worker"), {}}
+	if isWriting(idle) {
+		t.Errorf("Expected a parked stack not to be writing")
+	}
+}
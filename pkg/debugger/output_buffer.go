@@ -0,0 +1,91 @@
+package debugger
+
+import "time"
+
+// maxOutputLines bounds the program output kept per session; older lines are dropped
+const maxOutputLines = 10000
+
+// maxOutputBytes bounds the size of the output kept per session, as lines may
+// be long; older lines are dropped
+const maxOutputBytes = 16 << 20
+
+// outputBuffer is a ring buffer of output lines numbered in capture order.
+// Line numbers keep increasing after old lines are dropped, so they work as read cursors.
+type outputBuffer struct {
+	lines    []OutputMessage
+	start    int // Index of the oldest line in lines
+	count    int // Number of lines held
+	size     int // Bytes of content held
+	maxBytes int // Bytes of content held at most, beyond the newest line
+	nextSeq  int // Number of the next line to be appended
+}
+
+func newOutputBuffer(capacity int, maxBytes int) *outputBuffer {
+	return &outputBuffer{lines: make([]OutputMessage, capacity), maxBytes: maxBytes}
+}
+
+// append numbers and adds a line, dropping the oldest ones when the buffer is
+// full of lines or bytes
+func (b *outputBuffer) append(source string, content string, timestamp time.Time) OutputMessage {
+	line := OutputMessage{
+		Seq:       b.nextSeq,
+		Source:    source,
+		Content:   content,
+		Timestamp: timestamp,
+	}
+	b.nextSeq++
+
+	if b.count == len(b.lines) {
+		b.dropOldest()
+	}
+	for b.count > 0 && b.size+len(content) > b.maxBytes {
+		b.dropOldest()
+	}
+	b.lines[(b.start+b.count)%len(b.lines)] = line
+	b.count++
+	b.size += len(content)
+	return line
+}
+
+// dropOldest discards the oldest line held
+func (b *outputBuffer) dropOldest() {
+	b.size -= len(b.lines[b.start].Content)
+	b.lines[b.start] = OutputMessage{}
+	b.start = (b.start + 1) % len(b.lines)
+	b.count--
+}
+
+// firstSeq returns the number of the oldest line still held
+func (b *outputBuffer) firstSeq() int {
+	return b.nextSeq - b.count
+}
+
+// dropped returns how many lines were discarded to bound the buffer
+func (b *outputBuffer) dropped() int {
+	return b.firstSeq()
+}
+
+// since returns the held lines numbered seq or later, oldest first
+func (b *outputBuffer) since(seq int) []OutputMessage {
+	if seq < b.firstSeq() {
+		seq = b.firstSeq()
+	}
+	if seq >= b.nextSeq {
+		return nil
+	}
+
+	offset := seq - b.firstSeq()
+	result := make([]OutputMessage, 0, b.count-offset)
+	for i := offset; i < b.count; i++ {
+		result = append(result, b.lines[(b.start+i)%len(b.lines)])
+	}
+	return result
+}
+
+// tail returns the last n held lines, oldest first
+func (b *outputBuffer) tail(n int) []OutputMessage {
+	if n > b.count {
+		n = b.count
+	}
+	return b.since(b.nextSeq - n)
+}
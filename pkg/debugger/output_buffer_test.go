@@ -0,0 +1,103 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestOutputBuffer(t *testing.T) {
+	b := newOutputBuffer(3, maxOutputBytes)
+	for i := 0; i < 5; i++ {
+		b.append("stdout", fmt.Sprintf("line %d", i), time.Now())
+	}
+
+	contents := func(lines []OutputMessage) []string {
+		var result []string
+		for _, line := range lines {
+			result = append(result, fmt.Sprintf("%d:%s", line.Seq, line.Content))
+		}
+		return result
+	}
+
+	if b.dropped() != 2 || b.firstSeq() != 2 || b.nextSeq != 5 {
+		t.Fatalf("Expected 2 dropped lines, got dropped=%d first=%d next=%d", b.dropped(), b.firstSeq(), b.nextSeq)
+	}
+	if got, expected := contents(b.since(0)), []string{"2:line 2", "3:line 3", "4:line 4"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got, expected := contents(b.since(4)), []string{"4:line 4"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got := b.since(5); len(got) != 0 {
+		t.Errorf("Expected no lines after the cursor, got %v", contents(got))
+	}
+	if got, expected := contents(b.tail(2)), []string{"3:line 3", "4:line 4"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got := b.tail(10); len(got) != 3 {
+		t.Errorf("Expected tail to be limited to the held lines, got %v", contents(got))
+	}
+}
+
+func TestOutputBufferBoundsBytes(t *testing.T) {
+	b := newOutputBuffer(10, 10)
+	b.append("stdout", "aaaa", time.Now())
+	b.append("stdout", "bbbb", time.Now())
+	b.append("stdout", "cccc", time.Now())
+
+	if b.dropped() != 1 || b.size != 8 {
+		t.Fatalf("Expected the oldest line dropped to stay within 10 bytes, got dropped=%d size=%d", b.dropped(), b.size)
+	}
+	if got := b.since(0); len(got) != 2 || got[0].Content != "bbbb" {
+		t.Errorf("Expected the two newest lines, got %v", got)
+	}
+
+	// A line longer than the bound is still kept, alone
+	b.append("stderr", "dddddddddddd", time.Now())
+	if got := b.since(0); len(got) != 1 || got[0].Seq != 3 || b.dropped() != 3 {
+		t.Errorf("Expected only the long line after dropping 3, got %v (dropped %d)", got, b.dropped())
+	}
+}
+
+func TestSelectOutput(t *testing.T) {
+	lines := []OutputMessage{
+		{Seq: 0, Source: "stdout", Content: "level=info msg=start"},
+		{Seq: 1, Source: "stderr", Content: "level=error msg=boom"},
+		{Seq: 2, Source: "stdout", Content: "level=error msg=retry"},
+		{Seq: 3, Source: "stdout", Content: "level=info msg=done"},
+	}
+
+	testCases := []struct {
+		name     string
+		source   string
+		filter   string
+		tail     int
+		expected []int
+	}{
+		{name: "all", expected: []int{0, 1, 2, 3}},
+		{name: "source", source: "stdout", expected: []int{0, 2, 3}},
+		{name: "filter", filter: "level=error", expected: []int{1, 2}},
+		{name: "filter and tail", filter: "level=", tail: 2, expected: []int{2, 3}},
+		{name: "source and filter", source: "stdout", filter: "error", expected: []int{2}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var filter *regexp.Regexp
+			if tc.filter != "" {
+				filter = regexp.MustCompile(tc.filter)
+			}
+
+			var got []int
+			for _, line := range selectOutput(lines, tc.source, filter, tc.tail) {
+				got = append(got, line.Seq)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("Expected lines %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
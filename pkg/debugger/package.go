@@ -0,0 +1,191 @@
+package debugger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/gobuild"
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// goPackage is the subset of `go list -json` output needed to build a package
+type goPackage struct {
+	Dir          string
+	ImportPath   string
+	Name         string
+	TestGoFiles  []string
+	XTestGoFiles []string
+	Module       *struct {
+		Path  string
+		Dir   string
+		GoMod string
+	}
+	Error *struct {
+		Err string
+	}
+}
+
+// DebugPackage compiles and debugs a main package given by import path or directory
+func (c *Client) DebugPackage(pkg string, dir string, args []string, opts types.LaunchOptions) types.DebugPackageResponse {
+	response := types.DebugPackageResponse{
+		Package: pkg,
+		Args:    args,
+		Options: &opts,
+	}
+	if c.client != nil {
+		return c.createDebugPackageResponse(nil, &response, fmt.Errorf("debug session already active"))
+	}
+
+	resolved, err := resolvePackage(pkg, dir)
+	if err != nil {
+		return c.createDebugPackageResponse(nil, &response, err)
+	}
+	response.ImportPath = resolved.ImportPath
+	response.PackageDir = resolved.Dir
+	if resolved.Module != nil {
+		response.Module = resolved.Module.Path
+		response.ModuleDir = resolved.Module.Dir
+	}
+	response.Workspace = goWorkFile(resolved.Dir)
+
+	if resolved.Name != "main" {
+		return c.createDebugPackageResponse(nil, &response, fmt.Errorf("package %s is not a main package (package %s)", resolved.ImportPath, resolved.Name))
+	}
+
+	// Generate a unique debug binary outside the package directory
+	debugBinary, err := debugBinaryPath("debug_binary")
+	if err != nil {
+		return c.createDebugPackageResponse(nil, &response, err)
+	}
+	response.DebugBinary = debugBinary
+
+	logger.Debug("Compiling package %s in %s to %s", resolved.ImportPath, resolved.Dir, debugBinary)
+
+	// Build from the package directory (go build -C) so its module or workspace is used
+	buildFlags := "-C " + quoteBuildFlag(resolved.Dir) + " " + buildFlagsFor(opts)
+	cmd, output, err := gobuild.GoBuildCombinedOutput(debugBinary, []string{"."}, buildFlags)
+	response.BuildCommand = cmd
+	response.BuildOutput = string(output)
+	if err != nil {
+		logger.Debug("Build command: %s", cmd)
+		logger.Debug("Build output: %s", string(output))
+		gobuild.Remove(debugBinary)
+		response.BuildErrors, _ = parseBuildOutput(string(output), resolved.Dir)
+		return c.createDebugPackageResponse(nil, &response, fmt.Errorf("failed to compile package: %v\nOutput: %s", err, string(output)))
+	}
+
+	// Launch the compiled binary with the debugger
+	launchResponse := c.LaunchProgram(debugBinary, args, opts)
+	if launchResponse.Options != nil {
+		response.Options = launchResponse.Options
+	}
+	if launchResponse.Context.ErrorMessage != "" {
+		gobuild.Remove(debugBinary)
+		return c.createDebugPackageResponse(nil, &response, errors.New(launchResponse.Context.ErrorMessage))
+	}
+
+	// Store the binary path for cleanup
+	c.builtBinary = debugBinary
+
+	return c.createDebugPackageResponse(launchResponse.Context.DelveState, &response, nil)
+}
+
+// resolvePackage resolves a package pattern with module and workspace awareness.
+// Directory paths are listed from the directory itself so the module that
+// contains them is used; import paths are resolved from dir (default: current directory).
+func resolvePackage(pkg string, dir string) (*goPackage, error) {
+	if pkg == "" {
+		pkg = "."
+	}
+
+	listDir := dir
+	pattern := pkg
+	if isDirectoryPattern(pkg) {
+		path := pkg
+		if !filepath.IsAbs(path) && dir != "" {
+			path = filepath.Join(dir, path)
+		}
+		absDir, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path: %v", err)
+		}
+		if info, err := os.Stat(absDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("package directory not found: %s", absDir)
+		}
+		listDir = absDir
+		pattern = "."
+	}
+
+	cmd := goCommand(listDir, "list", "-e", "-json", pattern)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve package %s: %v\nOutput: %s", pkg, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// go list prints one JSON object per matched package
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	var packages []goPackage
+	for decoder.More() {
+		var p goPackage
+		if err := decoder.Decode(&p); err != nil {
+			return nil, fmt.Errorf("failed to parse go list output: %v", err)
+		}
+		packages = append(packages, p)
+	}
+
+	if len(packages) != 1 {
+		return nil, fmt.Errorf("package pattern %s matched %d packages, expected exactly one", pkg, len(packages))
+	}
+	// Packages with compile errors still resolve; the build reports those with positions
+	if packages[0].Error != nil && (packages[0].Dir == "" || packages[0].Name == "") {
+		return nil, fmt.Errorf("failed to resolve package %s: %s", pkg, packages[0].Error.Err)
+	}
+
+	return &packages[0], nil
+}
+
+// isDirectoryPattern reports whether a package argument refers to a directory rather than an import path
+func isDirectoryPattern(pkg string) bool {
+	return pkg == "." || pkg == ".." || filepath.IsAbs(pkg) ||
+		strings.HasPrefix(pkg, "./") || strings.HasPrefix(pkg, "../") ||
+		strings.HasPrefix(pkg, `.\`) || strings.HasPrefix(pkg, `..\`)
+}
+
+// goWorkFile returns the go.work file in effect for dir, if any
+func goWorkFile(dir string) string {
+	cmd := goCommand(dir, "env", "GOWORK")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	work := strings.TrimSpace(string(output))
+	if work == "off" {
+		return ""
+	}
+	return work
+}
+
+// createDebugPackageResponse creates a response for the debug package command
+func (c *Client) createDebugPackageResponse(state *api.DebuggerState, response *types.DebugPackageResponse, err error) types.DebugPackageResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "debug_package"
+	response.Context = &context
+	response.Status = "success"
+
+	if err != nil {
+		context.ErrorMessage = err.Error()
+		response.Status = "error"
+	}
+
+	return *response
+}
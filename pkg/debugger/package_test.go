@@ -0,0 +1,49 @@
+package debugger
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePackage(t *testing.T) {
+	testCases := []struct {
+		name       string
+		pkg        string
+		dir        string
+		importPath string
+		pkgName    string
+	}{
+		{
+			name:       "Relative directory",
+			pkg:        "./cmd/mcp-go-debugger",
+			dir:        "../..",
+			importPath: "github.com/sunfmin/mcp-go-debugger/cmd/mcp-go-debugger",
+			pkgName:    "main",
+		},
+		{
+			name:       "Import path",
+			pkg:        "github.com/sunfmin/mcp-go-debugger/pkg/types",
+			importPath: "github.com/sunfmin/mcp-go-debugger/pkg/types",
+			pkgName:    "types",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pkg, err := resolvePackage(tc.pkg, tc.dir)
+			if err != nil {
+				t.Fatalf("Failed to resolve package: %v", err)
+			}
+			if pkg.ImportPath != tc.importPath || pkg.Name != tc.pkgName {
+				t.Errorf("Expected %s (package %s), got %s (package %s)", tc.importPath, tc.pkgName, pkg.ImportPath, pkg.Name)
+			}
+			if pkg.Module == nil || pkg.Module.Path != "github.com/sunfmin/mcp-go-debugger" {
+				t.Errorf("Expected package to belong to the main module, got %+v", pkg.Module)
+			}
+		})
+	}
+
+	if _, err := resolvePackage(filepath.Join("..", "does-not-exist"), ""); err == nil {
+		t.Errorf("Expected an error for a missing package directory")
+	}
+}
@@ -0,0 +1,186 @@
+package debugger
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// procDir is where running processes are listed
+const procDir = "/proc"
+
+// ListProcesses lists the Go processes the current user can trace, optionally
+// only those whose name or command line matches pattern
+func ListProcesses(pattern string) types.ListProcessesResponse {
+	response := types.ListProcessesResponse{
+		Status: "success",
+		Context: types.DebugContext{
+			Timestamp: time.Now(),
+			Operation: "list_processes",
+		},
+	}
+
+	processes, err := findProcesses(procDir, pattern)
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+	response.Processes = processes
+
+	response.Summary = fmt.Sprintf("Found %d traceable Go processes", len(processes))
+	if pattern != "" {
+		response.Summary += fmt.Sprintf(" matching %q", pattern)
+	}
+	return response
+}
+
+// ResolveProcess finds the one traceable Go process whose name is spec or,
+// failing that, whose command line matches spec as a regular expression
+func ResolveProcess(spec string) (*types.Process, error) {
+	processes, err := findProcesses(procDir, "")
+	if err != nil {
+		return nil, err
+	}
+	return selectProcess(processes, spec)
+}
+
+// selectProcess picks the process named spec, or the single one matching it
+func selectProcess(processes []types.Process, spec string) (*types.Process, error) {
+	var named []types.Process
+	for _, process := range processes {
+		if process.Name == spec {
+			named = append(named, process)
+		}
+	}
+
+	matches := named
+	if len(matches) == 0 {
+		re, err := regexp.Compile(spec)
+		if err != nil {
+			return nil, fmt.Errorf("no Go process named %q, and it is not a valid pattern: %v", spec, err)
+		}
+		for _, process := range processes {
+			if matchesProcess(process, re) {
+				matches = append(matches, process)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no traceable Go process matches %q", spec)
+	case 1:
+		return &matches[0], nil
+	}
+
+	var candidates []string
+	for _, process := range matches {
+		candidates = append(candidates, fmt.Sprintf("%d (%s)", process.Pid, strings.Join(process.CmdLine, " ")))
+	}
+	return nil, fmt.Errorf("%d processes match %q, attach by pid instead: %s", len(matches), spec, strings.Join(candidates, ", "))
+}
+
+// matchesProcess reports whether the process name or command line matches re
+func matchesProcess(process types.Process, re *regexp.Regexp) bool {
+	return re.MatchString(process.Name) || re.MatchString(strings.Join(process.CmdLine, " "))
+}
+
+// findProcesses reads the processes under dir, keeping Go binaries whose
+// executable is readable. Processes of other users hide their executable,
+// which also means they cannot be traced.
+func findProcesses(dir string, pattern string) ([]types.Process, error) {
+	var re *regexp.Regexp
+	if pattern != "" {
+		var err error
+		if re, err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("invalid process pattern: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %v", err)
+	}
+
+	self := os.Getpid()
+	var processes []types.Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+
+		process, err := readProcess(dir, pid)
+		if err != nil {
+			continue
+		}
+		if re != nil && !matchesProcess(*process, re) {
+			continue
+		}
+		processes = append(processes, *process)
+	}
+
+	sort.Slice(processes, func(i, j int) bool { return processes[i].Pid < processes[j].Pid })
+	return processes, nil
+}
+
+// readProcess describes a running Go process, failing for processes that are
+// not Go programs or cannot be inspected
+func readProcess(dir string, pid int) (*types.Process, error) {
+	processDir := filepath.Join(dir, strconv.Itoa(pid))
+
+	executable, err := os.Readlink(filepath.Join(processDir, "exe"))
+	if err != nil {
+		return nil, err
+	}
+	info, err := buildinfo.ReadFile(filepath.Join(processDir, "exe"))
+	if err != nil {
+		return nil, fmt.Errorf("not a Go program: %v", err)
+	}
+
+	process := &types.Process{
+		Pid:        pid,
+		Name:       filepath.Base(strings.TrimSuffix(executable, " (deleted)")),
+		Executable: executable,
+		GoVersion:  info.GoVersion,
+		Module:     info.Path,
+		Status:     readProcessState(processDir),
+	}
+
+	if cmdline, err := os.ReadFile(filepath.Join(processDir, "cmdline")); err == nil {
+		process.CmdLine = strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+	}
+
+	process.Summary = fmt.Sprintf("%s (pid %d, %s) %s", process.Name, pid, process.GoVersion, process.Status)
+	return process, nil
+}
+
+// readProcessState returns the state from /proc/<pid>/status, such as "sleeping"
+func readProcessState(processDir string) string {
+	status, err := os.ReadFile(filepath.Join(processDir, "status"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		state, ok := strings.CutPrefix(line, "State:")
+		if !ok {
+			continue
+		}
+		// "S (sleeping)" -> "sleeping"
+		state = strings.TrimSpace(state)
+		if open := strings.Index(state, "("); open >= 0 {
+			state = strings.TrimSuffix(state[open+1:], ")")
+		}
+		return state
+	}
+	return ""
+}
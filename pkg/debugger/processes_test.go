@@ -0,0 +1,63 @@
+package debugger
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestReadProcess(t *testing.T) {
+	if _, err := os.Stat(procDir); err != nil {
+		t.Skip("no /proc on this system")
+	}
+
+	// The test binary is itself a Go program
+	process, err := readProcess(procDir, os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to read own process: %v", err)
+	}
+	if process.GoVersion != runtime.Version() {
+		t.Errorf("Expected Go version %s, got %s", runtime.Version(), process.GoVersion)
+	}
+	if len(process.CmdLine) == 0 || process.CmdLine[0] != os.Args[0] {
+		t.Errorf("Expected command line %v, got %v", os.Args, process.CmdLine)
+	}
+	if process.Name != "debugger.test" {
+		t.Errorf("Expected name debugger.test, got %s", process.Name)
+	}
+}
+
+func TestSelectProcess(t *testing.T) {
+	processes := []types.Process{
+		{Pid: 10, Name: "api", CmdLine: []string{"/srv/api", "-port", "8080"}},
+		{Pid: 11, Name: "worker", CmdLine: []string{"/srv/worker", "-queue", "emails"}},
+		{Pid: 12, Name: "worker", CmdLine: []string{"/srv/worker", "-queue", "reports"}},
+	}
+
+	tests := []struct {
+		spec    string
+		wantPid int
+		wantErr string
+	}{
+		{spec: "api", wantPid: 10},
+		{spec: "queue emails", wantPid: 11},
+		{spec: "worker", wantErr: "2 processes match"},
+		{spec: "billing", wantErr: "no traceable Go process"},
+	}
+
+	for _, tc := range tests {
+		process, err := selectProcess(processes, tc.spec)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%s: expected error containing %q, got %v", tc.spec, tc.wantErr, err)
+			}
+			continue
+		}
+		if err != nil || process.Pid != tc.wantPid {
+			t.Errorf("%s: expected pid %d, got %+v (%v)", tc.spec, tc.wantPid, process, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package debugger
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-delve/delve/service/api"
 	"net"
@@ -22,9 +23,9 @@ import (
 )
 
 // LaunchProgram starts a new program with debugging enabled
-func (c *Client) LaunchProgram(program string, args []string) types.LaunchResponse {
+func (c *Client) LaunchProgram(program string, args []string, opts types.LaunchOptions) types.LaunchResponse {
 	if c.client != nil {
-		return c.createLaunchResponse(nil, program, args, fmt.Errorf("debug session already active"))
+		return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("debug session already active"))
 	}
 
 	logger.Debug("Starting LaunchProgram for %s", program)
@@ -32,17 +33,30 @@ func (c *Client) LaunchProgram(program string, args []string) types.LaunchRespon
 	// Ensure program file exists and is executable
 	absPath, err := filepath.Abs(program)
 	if err != nil {
-		return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to get absolute path: %v", err))
+		return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("failed to get absolute path: %v", err))
 	}
 
 	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return c.createLaunchResponse(nil, program, args, fmt.Errorf("program file not found: %s", absPath))
+		return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("program file not found: %s", absPath))
+	}
+
+	workingDir, err := resolveWorkingDir(opts.Cwd)
+	if err != nil {
+		return c.createLaunchResponse(nil, program, args, &opts, err)
+	}
+	opts.Cwd = workingDir
+
+	if opts.FollowExecRegex != "" {
+		if _, err := regexp.Compile(opts.FollowExecRegex); err != nil {
+			return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("invalid followExecRegex: %v", err))
+		}
+		opts.FollowExec = true
 	}
 
 	// Get an available port for the debug server
 	port, err := getFreePort()
 	if err != nil {
-		return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to find available port: %v", err))
+		return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("failed to find available port: %v", err))
 	}
 
 	// Configure Delve logging
@@ -51,20 +65,66 @@ func (c *Client) LaunchProgram(program string, args []string) types.LaunchRespon
 	// Create a listener for the debug server
 	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
 	if err != nil {
-		return c.createLaunchResponse(nil, program, args, fmt.Errorf("couldn't start listener: %s", err))
+		return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("couldn't start listener: %s", err))
 	}
 
 	// Create pipes for stdout and stderr
 	stdoutReader, stdoutRedirect, err := proc.Redirector()
 	if err != nil {
-		return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to create stdout redirector: %v", err))
+		listener.Close()
+		return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("failed to create stdout redirector: %v", err))
 	}
 
 	stderrReader, stderrRedirect, err := proc.Redirector()
 	if err != nil {
+		listener.Close()
+		// Nothing reads the stdout redirect yet, so it is only discarded
 		stdoutRedirect.File.Close()
-		return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to create stderr redirector: %v", err))
+		if stdoutRedirect.Path != "" {
+			os.Remove(stdoutRedirect.Path)
+		}
+		return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("failed to create stderr redirector: %v", err))
+	}
+
+	// Start goroutines to capture output; on Unix the target opens the
+	// redirect FIFOs, which waits for these to open the other end
+	c.outputDone.Add(2)
+	go c.captureOutput(stdoutReader, "stdout")
+	go c.captureOutput(stderrReader, "stderr")
+	go c.forwardOutput()
+
+	launched := false
+	var process *os.Process
+	defer func() {
+		if launched {
+			return
+		}
+		// A failed launch leaves nothing running or open behind
+		if c.server != nil {
+			if err := c.server.Stop(); err != nil {
+				logger.Debug("Warning: Failed to stop debug server: %v", err)
+			}
+			c.server = nil
+		} else {
+			listener.Close()
+		}
+		if process != nil {
+			process.Kill()
+		}
+		c.stopCapture(stdoutRedirect, stderrRedirect)
+	}()
+
+	stdinPath, releaseStdin, err := c.setupStdin(opts)
+	if err != nil {
+		return c.createLaunchResponse(nil, program, args, &opts, err)
 	}
+	defer func() {
+		// The target holds its own copy of the stdin pipe once started
+		releaseStdin()
+		if !launched {
+			c.closeStdin()
+		}
+	}()
 
 	// Create Delve config
 	config := &service.Config{
@@ -73,23 +133,37 @@ func (c *Client) LaunchProgram(program string, args []string) types.LaunchRespon
 		AcceptMulti: true,
 		ProcessArgs: append([]string{absPath}, args...),
 		Debugger: debugger.Config{
-			WorkingDir:     "",
+			WorkingDir:     workingDir,
 			Backend:        "default",
 			CheckGoVersion: true,
 			DisableASLR:    true,
+			Stdin:          stdinPath,
 			Stdout:         stdoutRedirect,
 			Stderr:         stderrRedirect,
 		},
 	}
 
-	// Start goroutines to capture output
-	go c.captureOutput(stdoutReader, "stdout")
-	go c.captureOutput(stderrReader, "stderr")
+	// Delve starts the program with the environment of this process, which all
+	// sessions share, so a program with its own environment is started here
+	// and attached to before it runs
+	if env := launchEnv(opts); env != nil {
+		process, err = startStopped(config.ProcessArgs, workingDir, env, config.Debugger.DisableASLR, stdinPath, stdoutRedirect, stderrRedirect)
+		if err != nil {
+			return c.createLaunchResponse(nil, program, args, &opts, err)
+		}
+		// The program has its own copies of the output pipes
+		stdoutRedirect.File.Close()
+		stderrRedirect.File.Close()
+		config.Debugger.AttachPid = process.Pid
+		config.Debugger.Stdin = ""
+		config.Debugger.Stdout = proc.OutputRedirect{}
+		config.Debugger.Stderr = proc.OutputRedirect{}
+	}
 
 	// Create and start the debugging server
 	server := rpccommon.NewServer(config)
 	if server == nil {
-		return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to create debug server"))
+		return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("failed to create debug server"))
 	}
 
 	c.server = server
@@ -116,24 +190,39 @@ func (c *Client) LaunchProgram(program string, args []string) types.LaunchRespon
 	for !connected {
 		select {
 		case <-ctx.Done():
-			return c.createLaunchResponse(nil, program, args, fmt.Errorf("timed out waiting for debug server to start"))
+			return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("timed out waiting for debug server to start"))
 		case err := <-serverReady:
-			return c.createLaunchResponse(nil, program, args, fmt.Errorf("debug server failed to start: %v", err))
+			return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("debug server failed to start: %v", err))
 		default:
 			client := rpc2.NewClient(addr)
 			state, err := client.GetState()
 			if err == nil && state != nil {
+				// Children are only followed once they exec, so enable it before anything runs
+				if opts.FollowExec {
+					if err := client.FollowExec(true, opts.FollowExecRegex); err != nil {
+						client.Detach(true)
+						return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("failed to enable follow-exec: %v", err))
+					}
+				}
+
+				// Output capture is already running and reads the client under outputMutex
+				c.outputMutex.Lock()
 				c.client = client
+				c.outputMutex.Unlock()
 				c.target = absPath
+				c.started = process
+				c.pid = client.ProcessPid()
+				c.startTime = time.Now()
 				connected = true
+				launched = true
 
-				return c.createLaunchResponse(state, program, args, nil)
+				return c.createLaunchResponse(state, program, args, &opts, nil)
 			}
 			time.Sleep(100 * time.Millisecond)
 		}
 	}
 
-	return c.createLaunchResponse(nil, program, args, fmt.Errorf("failed to launch program"))
+	return c.createLaunchResponse(nil, program, args, &opts, fmt.Errorf("failed to launch program"))
 }
 
 // AttachToProcess attaches to an existing process with the given PID
@@ -232,11 +321,17 @@ func (c *Client) AttachToProcess(pid int) types.AttachResponse {
 				// Connection successful
 				c.client = client
 				c.pid = pid
+				c.startTime = time.Now()
 				connected = true
 				logger.Debug("Successfully attached to process with PID: %d", pid)
 
-				// Get initial state
-				return c.createAttachResponse(state, pid, "", nil, nil)
+				// Describe the process, best-effort as it may not be a Go binary we can read
+				process, err := readProcess(procDir, pid)
+				if err != nil {
+					logger.Debug("Warning: Failed to describe process %d: %v", pid, err)
+					return c.createAttachResponse(state, pid, "", nil, nil)
+				}
+				return c.createAttachResponse(state, pid, process.Executable, process, nil)
 			} else {
 				// Failed, wait briefly and retry
 				time.Sleep(100 * time.Millisecond)
@@ -247,8 +342,9 @@ func (c *Client) AttachToProcess(pid int) types.AttachResponse {
 	return c.createAttachResponse(nil, pid, "", nil, fmt.Errorf("failed to attach to process"))
 }
 
-// Close terminates the debug session
-func (c *Client) Close() (*types.CloseResponse, error) {
+// Close ends the debug session, killing the target when kill is set and
+// otherwise leaving it running. A server joined with connect keeps running.
+func (c *Client) Close(kill bool) (*types.CloseResponse, error) {
 	if c.client == nil {
 		return &types.CloseResponse{
 			Status: "success",
@@ -260,9 +356,20 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 		}, nil
 	}
 
+	// A launched program writes to pipes only this session drains and may run
+	// a binary built for it, so it cannot outlive the session
+	if !kill && c.target != "" && !c.exited {
+		return nil, fmt.Errorf("a launched program cannot keep running once its session closes; close it with kill, or use attach to debug a program that should outlive the session")
+	}
+
 	// Signal to stop output capturing goroutines
 	close(c.stopOutput)
 
+	// Unblock any pending stdin write
+	if err := c.closeStdin(); err != nil {
+		logger.Debug("Warning: Failed to close stdin: %v", err)
+	}
+
 	// Create a context with timeout to prevent indefinite hanging
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -272,7 +379,13 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 
 	// Attempt to detach from the debugger in a separate goroutine
 	go func() {
-		err := c.client.Detach(true)
+		var err error
+		if c.IsRemote() && !kill {
+			// Detach would also stop the headless server; only drop the connection
+			err = c.client.Disconnect(false)
+		} else {
+			err = c.client.Detach(kill)
+		}
 		if err != nil {
 			logger.Debug("Warning: Failed to detach from debugged process: %v", err)
 		}
@@ -289,14 +402,29 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 		detachErr = ctx.Err()
 	}
 
-	// Reset the client
+	// Delve detaches from programs it attached to, so one started with its own
+	// environment is killed here like the programs Delve launches
+	if c.started != nil {
+		if err := c.started.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+			logger.Debug("Warning: Failed to kill program: %v", err)
+		}
+		c.started = nil
+	}
+
+	// Reset the client; capture goroutines may still be reading lines and use it
+	// to halt on output, so it changes under outputMutex
+	c.outputMutex.Lock()
 	c.client = nil
+	c.outputMutex.Unlock()
+	// Another target may be built with a different Go release
+	c.waitReasonNames = nil
 
-	// Clean up the debug binary if it exists
-	if c.target != "" {
-		gobuild.Remove(c.target)
-		c.target = ""
+	// Clean up the debug binary if this session built it; a launched program is the user's
+	if c.builtBinary != "" {
+		gobuild.Remove(c.builtBinary)
+		c.builtBinary = ""
 	}
+	c.target = ""
 
 	// Create a new channel for server stop operations
 	stopChan := make(chan error, 1)
@@ -326,18 +454,26 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 		Operation: "close",
 	}
 
-	// Get exit code
-	exitCode := 0
-	if detachErr != nil {
-		exitCode = 1
-	}
-
 	// Create close response
 	response := &types.CloseResponse{
 		Status:   "success",
 		Context:  debugContext,
-		ExitCode: exitCode,
-		Summary:  fmt.Sprintf("Debug session closed with exit code %d", exitCode),
+		ExitCode: c.exitCode(),
+		Summary:  "Debug session closed; the program was terminated",
+	}
+	if c.exited {
+		response.Summary = fmt.Sprintf("Debug session closed; the program exited with status %d", c.exitStatus)
+		// Detaching from a process that is already gone is not a failure
+		detachErr = nil
+	}
+	switch {
+	case c.exited:
+	case c.coreFile != "":
+		response.Summary = "Debug session closed; the core dump was released"
+	case c.IsRemote() && !kill:
+		response.Summary = fmt.Sprintf("Disconnected from the Delve server at %s; it and the program keep running", c.remoteAddr)
+	case !kill:
+		response.Summary = "Debug session closed; the program was detached and keeps running"
 	}
 
 	logger.Debug("Close response: %+v", response)
@@ -345,137 +481,204 @@ func (c *Client) Close() (*types.CloseResponse, error) {
 }
 
 // DebugSourceFile compiles and debugs a Go source file
-func (c *Client) DebugSourceFile(sourceFile string, args []string) types.DebugSourceResponse {
+func (c *Client) DebugSourceFile(sourceFile string, args []string, opts types.LaunchOptions) types.DebugSourceResponse {
+	response := types.DebugSourceResponse{
+		SourceFile: sourceFile,
+		Args:       args,
+		Options:    &opts,
+	}
 	if c.client != nil {
-		return c.createDebugSourceResponse(nil, sourceFile, "", args, fmt.Errorf("debug session already active"))
+		return c.createDebugSourceResponse(nil, &response, fmt.Errorf("debug session already active"))
 	}
 
 	// Ensure source file exists
 	absPath, err := filepath.Abs(sourceFile)
 	if err != nil {
-		return c.createDebugSourceResponse(nil, sourceFile, "", args, fmt.Errorf("failed to get absolute path: %v", err))
+		return c.createDebugSourceResponse(nil, &response, fmt.Errorf("failed to get absolute path: %v", err))
 	}
 
 	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return c.createDebugSourceResponse(nil, sourceFile, "", args, fmt.Errorf("source file not found: %s", absPath))
+		return c.createDebugSourceResponse(nil, &response, fmt.Errorf("source file not found: %s", absPath))
 	}
 
-	// Generate a unique debug binary name
-	debugBinary := gobuild.DefaultDebugBinaryPath("debug_binary")
+	// Generate a unique debug binary outside the working directory
+	debugBinary, err := debugBinaryPath("debug_binary")
+	if err != nil {
+		return c.createDebugSourceResponse(nil, &response, err)
+	}
+	response.DebugBinary = debugBinary
 
 	logger.Debug("Compiling source file %s to %s", absPath, debugBinary)
 
 	// Compile the source file with output capture
-	cmd, output, err := gobuild.GoBuildCombinedOutput(debugBinary, []string{absPath}, "-gcflags all=-N")
+	cmd, output, err := gobuild.GoBuildCombinedOutput(debugBinary, []string{absPath}, buildFlagsFor(opts))
+	response.BuildCommand = cmd
 	if err != nil {
 		logger.Debug("Build command: %s", cmd)
 		logger.Debug("Build output: %s", string(output))
 		gobuild.Remove(debugBinary)
-		return c.createDebugSourceResponse(nil, sourceFile, debugBinary, args, fmt.Errorf("failed to compile source file: %v\nOutput: %s", err, string(output)))
+		buildDir, _ := os.Getwd()
+		response.BuildErrors, _ = parseBuildOutput(string(output), buildDir)
+		return c.createDebugSourceResponse(nil, &response, fmt.Errorf("failed to compile source file: %v\nOutput: %s", err, string(output)))
 	}
 
 	// Launch the compiled binary with the debugger
-	response := c.LaunchProgram(debugBinary, args)
-	if response.Context.ErrorMessage != "" {
+	launchResponse := c.LaunchProgram(debugBinary, args, opts)
+	if launchResponse.Options != nil {
+		response.Options = launchResponse.Options
+	}
+	if launchResponse.Context.ErrorMessage != "" {
 		gobuild.Remove(debugBinary)
-		return c.createDebugSourceResponse(nil, sourceFile, debugBinary, args, fmt.Errorf(response.Context.ErrorMessage))
+		return c.createDebugSourceResponse(nil, &response, errors.New(launchResponse.Context.ErrorMessage))
 	}
 
 	// Store the binary path for cleanup
-	c.target = debugBinary
+	c.builtBinary = debugBinary
 
-	return c.createDebugSourceResponse(response.Context.DelveState, sourceFile, debugBinary, args, nil)
+	return c.createDebugSourceResponse(launchResponse.Context.DelveState, &response, nil)
 }
 
-// DebugTest compiles and debugs a Go test function
-func (c *Client) DebugTest(testFilePath string, testName string, testFlags []string) types.DebugTestResponse {
+// stopOnFailureTimeout bounds how long debug_test runs to the first failure
+// before halting the program, so a test that hangs doesn't block the call
+const stopOnFailureTimeout = time.Minute
+
+// DebugTest compiles and debugs the tests of a package, selected by test file or package pattern
+func (c *Client) DebugTest(target types.TestTarget, testFlags []string, opts types.LaunchOptions) types.DebugTestResponse {
 	response := types.DebugTestResponse{
-		TestName:  testName,
-		TestFile:  testFilePath,
+		TestName:  target.TestName,
+		TestFile:  target.TestFile,
+		Package:   target.Package,
 		TestFlags: testFlags,
+		Options:   &opts,
 	}
 	if c.client != nil {
 		return c.createDebugTestResponse(nil, &response, fmt.Errorf("debug session already active"))
 	}
 
-	// Ensure test file exists
-	absPath, err := filepath.Abs(testFilePath)
-	if err != nil {
-		return c.createDebugTestResponse(nil, &response, fmt.Errorf("failed to get absolute path: %v", err))
+	if target.StopAtCase && target.TestName == "" {
+		return c.createDebugTestResponse(nil, &response, fmt.Errorf("stopping at a test case requires a test name"))
 	}
-
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return c.createDebugTestResponse(nil, &response, fmt.Errorf("test file not found: %s", absPath))
+	if target.Benchmark != "" && target.FuzzCase != "" {
+		return c.createDebugTestResponse(nil, &response, fmt.Errorf("a benchmark and a fuzz case cannot be debugged together"))
 	}
 
-	// Get the directory of the test file
-	testDir := filepath.Dir(absPath)
-	logger.Debug("Test directory: %s", testDir)
-
-	// Generate a unique debug binary name
-	debugBinary := gobuild.DefaultDebugBinaryPath("debug.test")
-
-	logger.Debug("Compiling test package in %s to %s", testDir, debugBinary)
-
-	// Save current directory
-	currentDir, err := os.Getwd()
+	// Get the directory of the package under test
+	testDir, err := resolveTestDir(target)
 	if err != nil {
-		return c.createDebugTestResponse(nil, &response, fmt.Errorf("failed to get current directory: %v", err))
+		return c.createDebugTestResponse(nil, &response, err)
 	}
+	response.PackageDir = testDir
+	logger.Debug("Test directory: %s", testDir)
 
-	// Change to test directory
-	if err := os.Chdir(testDir); err != nil {
-		return c.createDebugTestResponse(nil, &response, fmt.Errorf("failed to change to test directory: %v", err))
+	// Generate a unique debug binary outside the package directory
+	debugBinary, err := debugBinaryPath("debug.test")
+	if err != nil {
+		return c.createDebugTestResponse(nil, &response, err)
 	}
 
-	// Ensure we change back to original directory
-	defer func() {
-		if err := os.Chdir(currentDir); err != nil {
-			logger.Error("Failed to restore original directory: %v", err)
-		}
-	}()
+	logger.Debug("Compiling test package in %s to %s", testDir, debugBinary)
 
 	// Compile the test package with output capture using test-specific build flags
-	cmd, output, err := gobuild.GoTestBuildCombinedOutput(debugBinary, []string{testDir}, "-gcflags all=-N")
+	cmd, output, err := goTestBuild(testDir, debugBinary, buildFlagsFor(opts))
 	response.BuildCommand = cmd
 	response.BuildOutput = string(output)
 	if err != nil {
 		gobuild.Remove(debugBinary)
+		response.BuildErrors, response.VetErrors = parseBuildOutput(string(output), testDir)
 		return c.createDebugTestResponse(nil, &response, fmt.Errorf("failed to compile test package: %v\nOutput: %s", err, string(output)))
 	}
 
-	// Create args to run the specific test
+	// Create args to run the selected test, benchmark or fuzz case
 	args := []string{
 		"-test.v", // Verbose output
 	}
-
-	// Add specific test pattern if provided
-	if testName != "" {
-		// Escape special regex characters in the test name
-		escapedTestName := regexp.QuoteMeta(testName)
-		// Create a test pattern that matches exactly the provided test name
-		args = append(args, fmt.Sprintf("-test.run=^%s$", escapedTestName))
+	switch {
+	case target.Benchmark != "":
+		// Skip all tests and run the benchmark body exactly once
+		response.RunPattern = "^$"
+		response.BenchPattern = testRunPattern(target.Benchmark)
+		args = append(args, "-test.run="+response.RunPattern, "-test.bench="+response.BenchPattern, "-test.benchtime=1x")
+	case target.FuzzCase != "":
+		// Corpus entries run as subtests of the fuzz target named after their file
+		fuzzTarget, file, err := resolveFuzzCase(testDir, target.FuzzCase)
+		if err != nil {
+			gobuild.Remove(debugBinary)
+			return c.createDebugTestResponse(nil, &response, err)
+		}
+		response.FuzzCaseFile = file
+		response.RunPattern = testRunPattern(fuzzTarget + "/" + filepath.Base(file))
+		args = append(args, "-test.run="+response.RunPattern)
+	default:
+		// A raw run pattern is used as given, otherwise every level of the test name is matched exactly
+		runPattern := target.Run
+		if runPattern == "" && target.TestName != "" {
+			runPattern = testRunPattern(target.TestName)
+		}
+		if runPattern != "" {
+			args = append(args, "-test.run="+runPattern)
+		}
+		response.RunPattern = runPattern
 	}
 
 	// Add any additional test flags
 	args = append(args, testFlags...)
 
-	logger.Debug("Launching test binary with debugger, test name: %s, args: %v", testName, args)
+	// Tests run from their package directory, like go test does
+	if opts.Cwd == "" {
+		opts.Cwd = testDir
+	}
+	if target.FuzzCase != "" && filepath.Clean(opts.Cwd) != testDir {
+		gobuild.Remove(debugBinary)
+		return c.createDebugTestResponse(nil, &response, fmt.Errorf("fuzz cases must run in the package directory %s to find testdata/fuzz", testDir))
+	}
+
+	logger.Debug("Launching test binary with debugger, test name: %s, args: %v", target.TestName, args)
 	// Launch the compiled test binary with the debugger
-	response2 := c.LaunchProgram(debugBinary, args)
-	if response2.Context.ErrorMessage != "" {
+	launchResponse := c.LaunchProgram(debugBinary, args, opts)
+	if launchResponse.Options != nil {
+		response.Options = launchResponse.Options
+	}
+	if launchResponse.Context.ErrorMessage != "" {
 		gobuild.Remove(debugBinary)
-		return c.createDebugTestResponse(nil, &response, fmt.Errorf(response.Context.ErrorMessage))
+		return c.createDebugTestResponse(nil, &response, errors.New(launchResponse.Context.ErrorMessage))
 	}
 
 	// Store the binary path for cleanup
-	c.target = debugBinary
+	c.builtBinary = debugBinary
+
+	state := launchResponse.Context.DelveState
+	timedOut := false
+	if target.StopOnFailure {
+		err = c.setFailureBreakpoints()
+		if err == nil && !target.StopAtCase {
+			// Run straight to the first failure; with stopAtCase the breakpoints wait for a continue
+			state, timedOut, err = c.resume(stopOnFailureTimeout)
+		}
+	}
+	if err == nil && target.StopAtCase {
+		state, err = c.stopAtTestCase(target.TestName)
+		if err == nil {
+			response.StoppedAt = target.TestName
+		}
+	}
+	if err != nil {
+		// Don't leave a half-started session behind when the test can't be reached
+		if _, closeErr := c.Close(true); closeErr != nil {
+			logger.Debug("Warning: Failed to close debug session: %v", closeErr)
+		}
+		return c.createDebugTestResponse(nil, &response, err)
+	}
+	response.TestFailure = c.testFailure(state)
 
-	return c.createDebugTestResponse(response2.Context.DelveState, &response, nil)
+	result := c.createDebugTestResponse(state, &response, nil)
+	if timedOut {
+		result.Context.StopReason = fmt.Sprintf("halted: no test failed or exited within %v; continue to keep running to the first failure", stopOnFailureTimeout)
+	}
+	return result
 }
 
 // createLaunchResponse creates a response for the launch command
-func (c *Client) createLaunchResponse(state *api.DebuggerState, program string, args []string, err error) types.LaunchResponse {
+func (c *Client) createLaunchResponse(state *api.DebuggerState, program string, args []string, opts *types.LaunchOptions, err error) types.LaunchResponse {
 	context := c.createDebugContext(state)
 	context.Operation = "launch"
 
@@ -487,7 +690,8 @@ func (c *Client) createLaunchResponse(state *api.DebuggerState, program string,
 		Context:  &context,
 		Program:  program,
 		Args:     args,
-		ExitCode: 0,
+		Options:  opts,
+		ExitCode: context.ExitCode,
 	}
 }
 
@@ -510,21 +714,17 @@ func (c *Client) createAttachResponse(state *api.DebuggerState, pid int, target
 }
 
 // createDebugSourceResponse creates a response for the debug source command
-func (c *Client) createDebugSourceResponse(state *api.DebuggerState, sourceFile string, debugBinary string, args []string, err error) types.DebugSourceResponse {
+func (c *Client) createDebugSourceResponse(state *api.DebuggerState, response *types.DebugSourceResponse, err error) types.DebugSourceResponse {
 	context := c.createDebugContext(state)
 	context.Operation = "debug_source"
+	response.Context = &context
+	response.Status = "success"
 
 	if err != nil {
 		context.ErrorMessage = err.Error()
 	}
 
-	return types.DebugSourceResponse{
-		Status:      "success",
-		Context:     &context,
-		SourceFile:  sourceFile,
-		DebugBinary: debugBinary,
-		Args:        args,
-	}
+	return *response
 }
 
 // createDebugTestResponse creates a response for the debug test command
@@ -0,0 +1,224 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// snapshotStackDepth is how many frames of each goroutine identify its stack
+const snapshotStackDepth = 20
+
+// goroutineSnapshot is the goroutine set of the program at one point in time
+type goroutineSnapshot struct {
+	takenAt    time.Time
+	goroutines []snapshotGoroutine
+}
+
+// snapshotGoroutine is a goroutine in a snapshot with the key grouping it
+type snapshotGoroutine struct {
+	goroutine types.Goroutine
+	stack     []string // Function names, innermost first
+	key       string   // Creation site, start function and stack
+}
+
+// SnapshotGoroutines records the current goroutines under name, replacing an
+// earlier snapshot with the same name
+func (c *Client) SnapshotGoroutines(name string) types.GoroutineSnapshotResponse {
+	if c.client == nil {
+		return c.createSnapshotResponse(nil, name, nil, fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return c.createSnapshotResponse(nil, name, nil, c.exitedError())
+	}
+	if name == "" {
+		return c.createSnapshotResponse(nil, name, nil, fmt.Errorf("snapshot name is required"))
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createSnapshotResponse(nil, name, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	goroutines, err := c.allGoroutines()
+	if err != nil {
+		return c.createSnapshotResponse(state, name, nil, err)
+	}
+
+	reasons := c.waitReasons()
+	snapshot := &goroutineSnapshot{takenAt: time.Now()}
+	for _, g := range goroutines {
+		entry := snapshotGoroutine{goroutine: convertGoroutine(g, nil, reasons)}
+		frames, err := c.client.Stacktrace(g.ID, snapshotStackDepth, 0, nil)
+		if err != nil {
+			logger.Debug("Warning: Failed to get stack of goroutine %d: %v", g.ID, err)
+		}
+		for _, frame := range frames {
+			entry.stack = append(entry.stack, frameFunction(frame))
+		}
+		entry.key = snapshotKey(entry)
+		snapshot.goroutines = append(snapshot.goroutines, entry)
+	}
+
+	if c.goroutineSnapshots == nil {
+		c.goroutineSnapshots = make(map[string]*goroutineSnapshot)
+	}
+	c.goroutineSnapshots[name] = snapshot
+
+	return c.createSnapshotResponse(state, name, snapshot, nil)
+}
+
+// DiffGoroutineSnapshots reports the goroutines that appeared or disappeared
+// between two snapshots, grouped by creation site and stack
+func (c *Client) DiffGoroutineSnapshots(from string, to string) types.GoroutineDiffResponse {
+	before, ok := c.goroutineSnapshots[from]
+	if !ok {
+		return c.createGoroutineDiffResponse(from, to, nil, nil, fmt.Errorf("no goroutine snapshot named %q", from))
+	}
+	after, ok := c.goroutineSnapshots[to]
+	if !ok {
+		return c.createGoroutineDiffResponse(from, to, nil, nil, fmt.Errorf("no goroutine snapshot named %q", to))
+	}
+
+	appeared, disappeared := diffSnapshots(before, after)
+	return c.createGoroutineDiffResponse(from, to, appeared, disappeared, nil)
+}
+
+// diffSnapshots compares goroutine counts per key. Comparing counts rather
+// than IDs keeps a pool that replaced its workers from showing up as a leak.
+func diffSnapshots(before, after *goroutineSnapshot) (appeared, disappeared []types.GoroutineDelta) {
+	beforeIDs := make(map[int64]bool)
+	for _, g := range before.goroutines {
+		beforeIDs[g.goroutine.ID] = true
+	}
+	afterIDs := make(map[int64]bool)
+	for _, g := range after.goroutines {
+		afterIDs[g.goroutine.ID] = true
+	}
+
+	groups := make(map[string]*types.GoroutineDelta)
+	var keys []string
+	group := func(g snapshotGoroutine) *types.GoroutineDelta {
+		delta, ok := groups[g.key]
+		if !ok {
+			delta = &types.GoroutineDelta{
+				CreatedBy:     g.goroutine.GoStatementLoc,
+				StartLocation: g.goroutine.StartLocation,
+				Location:      g.goroutine.Location,
+				WaitReason:    g.goroutine.WaitReason,
+				Stack:         g.stack,
+			}
+			groups[g.key] = delta
+			keys = append(keys, g.key)
+		}
+		return delta
+	}
+
+	for _, g := range before.goroutines {
+		delta := group(g)
+		delta.Before++
+		if !afterIDs[g.goroutine.ID] && len(delta.GoroutineIDs) < 20 {
+			delta.GoroutineIDs = append(delta.GoroutineIDs, g.goroutine.ID)
+		}
+	}
+	for _, g := range after.goroutines {
+		delta := group(g)
+		delta.After++
+		if !beforeIDs[g.goroutine.ID] && len(delta.GoroutineIDs) < 20 {
+			delta.GoroutineIDs = append(delta.GoroutineIDs, g.goroutine.ID)
+		}
+	}
+
+	for _, key := range keys {
+		delta := groups[key]
+		delta.Delta = delta.After - delta.Before
+		switch {
+		case delta.Delta > 0:
+			appeared = append(appeared, *delta)
+		case delta.Delta < 0:
+			disappeared = append(disappeared, *delta)
+		}
+	}
+
+	sort.SliceStable(appeared, func(i, j int) bool { return appeared[i].Delta > appeared[j].Delta })
+	sort.SliceStable(disappeared, func(i, j int) bool { return disappeared[i].Delta < disappeared[j].Delta })
+	return appeared, disappeared
+}
+
+// snapshotKey identifies goroutines started at the same place that are blocked in the same stack
+func snapshotKey(g snapshotGoroutine) string {
+	return g.goroutine.GoStatementLoc + "\x00" + g.goroutine.StartLocation + "\x00" + strings.Join(g.stack, "\x00")
+}
+
+// frameFunction returns the function of a frame, without the line that moves with every step
+func frameFunction(frame api.Stackframe) string {
+	if frame.Function == nil {
+		return "?"
+	}
+	return frame.Function.Name()
+}
+
+// createSnapshotResponse creates a response for the snapshot goroutines command
+func (c *Client) createSnapshotResponse(state *api.DebuggerState, name string, snapshot *goroutineSnapshot, err error) types.GoroutineSnapshotResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "snapshot_goroutines"
+
+	response := types.GoroutineSnapshotResponse{
+		Status:  "success",
+		Context: context,
+		Name:    name,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.Count = len(snapshot.goroutines)
+	response.TakenAt = snapshot.takenAt
+	for snapshotName := range c.goroutineSnapshots {
+		response.Snapshots = append(response.Snapshots, snapshotName)
+	}
+	sort.Strings(response.Snapshots)
+	response.Summary = fmt.Sprintf("Recorded %d goroutines as snapshot %q", response.Count, name)
+	return response
+}
+
+// createGoroutineDiffResponse creates a response for the diff goroutine snapshots command
+func (c *Client) createGoroutineDiffResponse(from, to string, appeared, disappeared []types.GoroutineDelta, err error) types.GoroutineDiffResponse {
+	response := types.GoroutineDiffResponse{
+		Status: "success",
+		Context: types.DebugContext{
+			Timestamp: time.Now(),
+			Operation: "diff_goroutine_snapshots",
+		},
+		From:        from,
+		To:          to,
+		Appeared:    appeared,
+		Disappeared: disappeared,
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	var added, removed int
+	for _, delta := range appeared {
+		added += delta.Delta
+	}
+	for _, delta := range disappeared {
+		removed -= delta.Delta
+	}
+	response.Summary = fmt.Sprintf("From %q to %q: %d goroutines appeared in %d groups, %d disappeared in %d groups",
+		from, to, added, len(appeared), removed, len(disappeared))
+	if len(appeared) > 0 && appeared[0].CreatedBy != "" {
+		response.Summary += fmt.Sprintf("; most new goroutines were created by %s", appeared[0].CreatedBy)
+	}
+	return response
+}
@@ -0,0 +1,46 @@
+package debugger
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	entry := func(id int64, createdBy string, stack ...string) snapshotGoroutine {
+		g := snapshotGoroutine{
+			goroutine: types.Goroutine{ID: id, GoStatementLoc: createdBy, Location: stack[0]},
+			stack:     stack,
+		}
+		g.key = snapshotKey(g)
+		return g
+	}
+
+	before := &goroutineSnapshot{goroutines: []snapshotGoroutine{
+		entry(1, "", "main.main"),
+		entry(5, "main.startPool at /src/pool.go:10", "main.worker"),
+		entry(6, "main.startTimer at /src/timer.go:4", "time.Sleep"),
+	}}
+	// The pool replaced its worker, the timer finished and the handler left two goroutines behind
+	after := &goroutineSnapshot{goroutines: []snapshotGoroutine{
+		entry(1, "", "main.main"),
+		entry(9, "main.startPool at /src/pool.go:10", "main.worker"),
+		entry(10, "main.handle at /src/handler.go:22", "runtime.chanrecv1", "main.fetch"),
+		entry(11, "main.handle at /src/handler.go:22", "runtime.chanrecv1", "main.fetch"),
+	}}
+
+	appeared, disappeared := diffSnapshots(before, after)
+	if len(appeared) != 1 {
+		t.Fatalf("Expected one group to appear, got %+v", appeared)
+	}
+	if appeared[0].CreatedBy != "main.handle at /src/handler.go:22" || appeared[0].Delta != 2 {
+		t.Errorf("Expected two goroutines from the handler, got %+v", appeared[0])
+	}
+	if !reflect.DeepEqual(appeared[0].GoroutineIDs, []int64{10, 11}) {
+		t.Errorf("Expected goroutines 10 and 11, got %v", appeared[0].GoroutineIDs)
+	}
+	if len(disappeared) != 1 || disappeared[0].CreatedBy != "main.startTimer at /src/timer.go:4" || disappeared[0].Delta != -1 {
+		t.Errorf("Expected the timer goroutine to disappear, got %+v", disappeared)
+	}
+}
@@ -0,0 +1,181 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// setupStdin prepares the standard input of the debuggee and returns the path
+// Delve should open as stdin ("" to keep Delve's default). Inline text and
+// write_stdin go through a pipe whose write end stays with the client, copied
+// to a FIFO the target opens. The returned release function removes the FIFO
+// once the target has opened it.
+func (c *Client) setupStdin(opts types.LaunchOptions) (string, func(), error) {
+	release := func() {}
+
+	if opts.Stdin != "" {
+		if opts.StdinText != "" || opts.StdinPipe {
+			return "", release, fmt.Errorf("stdin can be a file or inline text and a pipe, not both")
+		}
+		path, err := filepath.Abs(opts.Stdin)
+		if err != nil {
+			return "", release, fmt.Errorf("failed to get absolute path of stdin file: %v", err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", release, fmt.Errorf("stdin file not found: %s", path)
+		}
+		if info.IsDir() {
+			return "", release, fmt.Errorf("stdin file is a directory: %s", path)
+		}
+		return path, release, nil
+	}
+
+	if opts.StdinText == "" && !opts.StdinPipe {
+		return "", release, nil
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return "", release, fmt.Errorf("failed to create stdin pipe: %v", err)
+	}
+	path, release, err := stdinFifo(reader)
+	if err != nil {
+		reader.Close()
+		writer.Close()
+		return "", func() {}, err
+	}
+
+	c.stdin = writer
+
+	if opts.StdinText != "" {
+		// Write in the background: the pipe buffer is small and the program may
+		// not read yet. Holding the lock keeps later writes after this text.
+		c.stdinMu.Lock()
+		go func() {
+			if _, err := c.stdin.WriteString(opts.StdinText); err != nil {
+				logger.Debug("Warning: Failed to write stdin text: %v", err)
+			}
+			c.stdinMu.Unlock()
+			if !opts.StdinPipe {
+				c.closeStdin()
+			}
+		}()
+	}
+
+	return path, release, nil
+}
+
+// writeStdin writes data to the stdin pipe of the debuggee, giving up at the
+// deadline (zero for none). Writes are serialized so their data doesn't interleave.
+func (c *Client) writeStdin(data string, deadline time.Time) (int, error) {
+	if c.stdin == nil {
+		return 0, fmt.Errorf("stdin is not open; launch with stdinPipe to write to it")
+	}
+	if c.stdinClosed.Load() {
+		return 0, fmt.Errorf("stdin has been closed")
+	}
+
+	// An earlier write, such as the launch's stdin text, may itself be stuck
+	if !c.lockStdin(deadline) {
+		return 0, fmt.Errorf("earlier input is still being written: %w", os.ErrDeadlineExceeded)
+	}
+	defer c.stdinMu.Unlock()
+
+	if err := c.stdin.SetWriteDeadline(deadline); err != nil {
+		return 0, fmt.Errorf("failed to set stdin write deadline: %v", err)
+	}
+	return c.stdin.WriteString(data)
+}
+
+// closeStdin closes the stdin pipe so the debuggee reads EOF. It doesn't wait
+// for pending writes, which fail once the pipe is closed.
+func (c *Client) closeStdin() error {
+	if c.stdin == nil || c.stdinClosed.Swap(true) {
+		return nil
+	}
+	return c.stdin.Close()
+}
+
+// lockStdin acquires stdinMu, giving up at the deadline (zero for none)
+func (c *Client) lockStdin(deadline time.Time) bool {
+	if deadline.IsZero() {
+		c.stdinMu.Lock()
+		return true
+	}
+	for !c.stdinMu.TryLock() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// waitForStdinWrites waits up to timeout for pending stdin writes to complete
+func (c *Client) waitForStdinWrites(timeout time.Duration) bool {
+	if !c.lockStdin(time.Now().Add(timeout)) {
+		return false
+	}
+	c.stdinMu.Unlock()
+	return true
+}
+
+// WriteStdin sends data to the standard input of the debuggee and optionally
+// closes it afterwards to signal EOF
+func (c *Client) WriteStdin(data string, closeAfter bool) types.WriteStdinResponse {
+	response := types.WriteStdinResponse{
+		Status: "success",
+		Context: types.DebugContext{
+			Timestamp: time.Now(),
+			Operation: "write_stdin",
+		},
+	}
+
+	fail := func(err error) types.WriteStdinResponse {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	if c.client == nil {
+		return fail(fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return fail(c.exitedError())
+	}
+	if err := c.readOnlyError("write_stdin"); err != nil {
+		return fail(err)
+	}
+
+	if data != "" {
+		// A program that stopped reading fills the pipe; don't hang the tool call on it
+		n, err := c.writeStdin(data, time.Now().Add(5*time.Second))
+		response.BytesWritten = n
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return fail(fmt.Errorf("timed out writing to stdin after %d bytes; the program is not reading it (is it stopped at a breakpoint?)", n))
+		}
+		if err != nil {
+			return fail(fmt.Errorf("failed to write to stdin: %v", err))
+		}
+	}
+
+	if closeAfter {
+		// Let earlier input reach the pipe before EOF, unless the program isn't reading it
+		if !c.waitForStdinWrites(5 * time.Second) {
+			return fail(fmt.Errorf("timed out waiting for earlier input to be read before closing stdin"))
+		}
+		if err := c.closeStdin(); err != nil {
+			return fail(fmt.Errorf("failed to close stdin: %v", err))
+		}
+		response.Closed = true
+	}
+
+	return response
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package debugger
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// stdinFifo returns the path of a FIFO feeding the debuggee what is written to
+// the pipe read by reader, for Delve's stdin redirect
+func stdinFifo(reader *os.File) (string, func(), error) {
+	return "", nil, fmt.Errorf("stdin pipes are not supported on %s; use a stdin file instead", runtime.GOOS)
+}
@@ -0,0 +1,144 @@
+//go:build unix
+
+package debugger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+func TestSetupStdinPipe(t *testing.T) {
+	c := NewClient()
+	path, release, err := c.setupStdin(types.LaunchOptions{StdinText: "first\n", StdinPipe: true})
+	if err != nil {
+		t.Fatalf("Failed to set up stdin: %v", err)
+	}
+
+	// Delve opens the path as the target's stdin; the client's read end can go afterwards
+	stdin, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open stdin path %s: %v", path, err)
+	}
+	defer stdin.Close()
+	release()
+
+	read := make(chan string, 1)
+	go func() {
+		data, _ := io.ReadAll(stdin)
+		read <- string(data)
+	}()
+
+	if _, err := c.writeStdin("second\n", time.Now().Add(5*time.Second)); err != nil {
+		t.Fatalf("Failed to write stdin: %v", err)
+	}
+	if !c.waitForStdinWrites(5 * time.Second) {
+		t.Fatal("Timed out waiting for stdin writes")
+	}
+	if err := c.closeStdin(); err != nil {
+		t.Fatalf("Failed to close stdin: %v", err)
+	}
+
+	select {
+	case data := <-read:
+		if data != "first\nsecond\n" {
+			t.Errorf("Expected both writes followed by EOF, got %q", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for EOF on stdin")
+	}
+
+	if _, err := c.writeStdin("late\n", time.Time{}); err == nil {
+		t.Error("Expected writing to closed stdin to fail")
+	}
+}
+
+func TestSetupStdinPipeFromAnotherProcess(t *testing.T) {
+	c := NewClient()
+	path, release, err := c.setupStdin(types.LaunchOptions{StdinText: "hello\n"})
+	if err != nil {
+		t.Fatalf("Failed to set up stdin: %v", err)
+	}
+	if strings.HasPrefix(path, "/dev/fd/") {
+		t.Fatalf("Expected a path other processes can open, got %s", path)
+	}
+
+	// With debugserver, a process other than this one opens the path
+	cmd := exec.Command("cat", path)
+	done := make(chan struct{})
+	var output []byte
+	go func() {
+		output, err = cmd.Output()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		cmd.Process.Kill()
+		t.Fatal("Timed out waiting for the other process to read stdin")
+	}
+	release()
+	if err != nil || string(output) != "hello\n" {
+		t.Errorf("Expected the stdin text, got %q (%v)", output, err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the FIFO to be removed on release")
+	}
+}
+
+func TestSetupStdinFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(file, []byte("input\n"), 0644); err != nil {
+		t.Fatalf("Failed to write stdin file: %v", err)
+	}
+
+	c := NewClient()
+	path, _, err := c.setupStdin(types.LaunchOptions{Stdin: file})
+	if err != nil || path != file {
+		t.Fatalf("Expected stdin path %s, got %s (%v)", file, path, err)
+	}
+
+	if _, _, err := c.setupStdin(types.LaunchOptions{Stdin: file, StdinPipe: true}); err == nil {
+		t.Error("Expected a stdin file combined with a pipe to be rejected")
+	}
+	if _, _, err := c.setupStdin(types.LaunchOptions{Stdin: filepath.Dir(file)}); err == nil {
+		t.Error("Expected a directory as stdin to be rejected")
+	}
+	if path, _, err := c.setupStdin(types.LaunchOptions{}); err != nil || path != "" {
+		t.Errorf("Expected the default stdin, got %q (%v)", path, err)
+	}
+}
+
+func TestWriteStdinBehindUnreadText(t *testing.T) {
+	c := NewClient()
+	// More than a pipe buffer holds, so the background write of the text blocks
+	text := strings.Repeat("x", 1<<20)
+	path, release, err := c.setupStdin(types.LaunchOptions{StdinText: text, StdinPipe: true})
+	if err != nil {
+		t.Fatalf("Failed to set up stdin: %v", err)
+	}
+	stdin, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open stdin path %s: %v", path, err)
+	}
+	defer stdin.Close()
+	release()
+	defer c.closeStdin()
+
+	start := time.Now()
+	_, err = c.writeStdin("more\n", time.Now().Add(200*time.Millisecond))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("Expected a deadline error while the text is unread, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Write gave up after %s, long past its deadline", elapsed)
+	}
+}
@@ -0,0 +1,56 @@
+//go:build unix
+
+package debugger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+)
+
+// stdinFifo returns the path of a FIFO feeding the debuggee what is written to
+// the pipe read by reader. Delve opens stdin by path, and with the debugserver
+// backend another process opens it, so the path must name a file rather than a
+// descriptor of this process. The returned release function removes the FIFO
+// once the debuggee has had its chance to open it.
+func stdinFifo(reader *os.File) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "mcp-go-debugger-stdin")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create stdin directory: %v", err)
+	}
+	path := filepath.Join(dir, "stdin")
+	if err := syscall.Mkfifo(path, 0600); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("failed to create stdin FIFO: %v", err)
+	}
+
+	go func() {
+		defer reader.Close()
+
+		// Opening the write end waits until the debuggee opens the read end
+		fifo, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			logger.Debug("Warning: Failed to open stdin FIFO: %v", err)
+			return
+		}
+		defer fifo.Close()
+
+		// Closing the pipe ends the copy, and the debuggee reads EOF
+		if _, err := io.Copy(fifo, reader); err != nil {
+			logger.Debug("Warning: Stopped copying stdin: %v", err)
+		}
+	}()
+
+	release := func() {
+		// A reader that comes and goes lets the copy start when the debuggee never opened the FIFO
+		if f, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0); err == nil {
+			f.Close()
+		}
+		os.RemoveAll(dir)
+	}
+	return path, release, nil
+}
@@ -0,0 +1,352 @@
+package debugger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// Keys holding the standard fields in log/slog, zap, logrus and similar loggers, in lookup order
+var (
+	levelKeys   = []string{"level", "lvl", "severity"}
+	messageKeys = []string{"msg", "message"}
+	timeKeys    = []string{"time", "ts", "timestamp"}
+)
+
+// levelRanks orders normalized levels for minimum-level filtering
+var levelRanks = map[string]int{
+	"trace":    0,
+	"debug":    1,
+	"info":     2,
+	"warn":     3,
+	"error":    4,
+	"dpanic":   5,
+	"panic":    5,
+	"fatal":    5,
+	"critical": 5,
+}
+
+// validateLogQuery checks the structured log options of an output query
+func validateLogQuery(query types.OutputQuery) error {
+	switch query.Format {
+	case "", "json", "logfmt", "auto":
+	default:
+		return fmt.Errorf("format must be json, logfmt or auto, got %q", query.Format)
+	}
+	if query.Format == "" && (query.Level != "" || len(query.Attrs) > 0) {
+		return fmt.Errorf("level and attrs filters require a format")
+	}
+	if query.Level != "" {
+		if _, ok := levelRanks[normalizeLevel(query.Level)]; !ok {
+			return fmt.Errorf("unknown level %q", query.Level)
+		}
+	}
+	return nil
+}
+
+// parseLogLines parses output lines in the given format. Lines that do not
+// parse are kept as unstructured records holding the raw line as message.
+func parseLogLines(lines []OutputMessage, format string) []types.LogRecord {
+	records := make([]types.LogRecord, 0, len(lines))
+	for _, line := range lines {
+		record, ok := parseLogLine(line.Content, format)
+		if !ok {
+			record = types.LogRecord{Message: line.Content, Unstructured: true}
+		}
+		record.LineNumber = line.Seq
+		record.Source = line.Source
+		records = append(records, record)
+	}
+	return records
+}
+
+// parseLogLine parses one line as a JSON or logfmt log record
+func parseLogLine(content string, format string) (types.LogRecord, bool) {
+	var fields map[string]string
+	var ok bool
+
+	trimmed := strings.TrimSpace(content)
+	switch {
+	case format == "json", format == "auto" && strings.HasPrefix(trimmed, "{"):
+		fields, ok = parseJSONFields(trimmed)
+	default:
+		fields, ok = parseLogfmtFields(trimmed)
+		// Plain text often contains "=" too; require one of the standard keys
+		ok = ok && (takeField(fields, messageKeys, false) != "" || takeField(fields, levelKeys, false) != "")
+	}
+	if !ok {
+		return types.LogRecord{}, false
+	}
+
+	record := types.LogRecord{
+		Level:   normalizeLevel(takeField(fields, levelKeys, true)),
+		Message: takeField(fields, messageKeys, true),
+		Time:    takeField(fields, timeKeys, true),
+	}
+	if len(fields) > 0 {
+		record.Attrs = fields
+	}
+	return record, true
+}
+
+// takeField returns the value of the first of keys present, removing it when remove is set
+func takeField(fields map[string]string, keys []string, remove bool) string {
+	for _, key := range keys {
+		if value, ok := fields[key]; ok {
+			if remove {
+				delete(fields, key)
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// parseJSONFields parses a JSON object, flattening nested objects such as slog
+// groups into dotted keys
+func parseJSONFields(content string) (map[string]string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil {
+		return nil, false
+	}
+
+	fields := make(map[string]string, len(object))
+	flattenJSON(fields, "", object)
+	return fields, true
+}
+
+func flattenJSON(fields map[string]string, prefix string, object map[string]interface{}) {
+	for key, value := range object {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flattenJSON(fields, prefix+key+".", v)
+		case string:
+			fields[prefix+key] = v
+		case nil:
+			fields[prefix+key] = "null"
+		case json.Number, bool:
+			fields[prefix+key] = fmt.Sprint(v)
+		default:
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.Encode(v)
+			fields[prefix+key] = strings.TrimSpace(buf.String())
+		}
+	}
+}
+
+// parseLogfmtFields parses key=value pairs, with optionally quoted values, as
+// written by slog's TextHandler and logfmt loggers. Bare keys get an empty value.
+func parseLogfmtFields(content string) (map[string]string, bool) {
+	fields := make(map[string]string)
+	i := 0
+	for i < len(content) {
+		if content[i] == ' ' || content[i] == '\t' {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(content) && content[i] != '=' && content[i] != ' ' && content[i] != '\t' {
+			if content[i] == '"' {
+				return nil, false
+			}
+			i++
+		}
+		key := content[start:i]
+		if key == "" {
+			return nil, false
+		}
+		if i == len(content) || content[i] != '=' {
+			fields[key] = ""
+			continue
+		}
+		i++
+
+		if i < len(content) && content[i] == '"' {
+			end := i + 1
+			for end < len(content) && content[end] != '"' {
+				if content[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(content) {
+				return nil, false
+			}
+			value, err := strconv.Unquote(content[i : end+1])
+			if err != nil {
+				return nil, false
+			}
+			fields[key] = value
+			i = end + 1
+			continue
+		}
+
+		start = i
+		for i < len(content) && content[i] != ' ' && content[i] != '\t' {
+			i++
+		}
+		fields[key] = content[start:i]
+	}
+	return fields, len(fields) > 0
+}
+
+// normalizeLevel lowercases a level and maps aliases, slog offsets such as
+// "INFO+2" and numeric pino/bunyan levels onto the names in levelRanks
+func normalizeLevel(level string) string {
+	level = strings.ToLower(strings.TrimSpace(level))
+	if i := strings.IndexAny(level, "+-"); i > 0 {
+		level = level[:i]
+	}
+
+	switch level {
+	case "warning":
+		return "warn"
+	case "err":
+		return "error"
+	case "crit":
+		return "critical"
+	case "10":
+		return "trace"
+	case "20":
+		return "debug"
+	case "30":
+		return "info"
+	case "40":
+		return "warn"
+	case "50":
+		return "error"
+	case "60":
+		return "fatal"
+	}
+	return level
+}
+
+// filterLogRecords keeps the records at or above minLevel that carry all of attrs
+func filterLogRecords(records []types.LogRecord, minLevel string, attrs map[string]string) []types.LogRecord {
+	if minLevel == "" && len(attrs) == 0 {
+		return records
+	}
+
+	minRank := levelRanks[normalizeLevel(minLevel)]
+	var kept []types.LogRecord
+	for _, record := range records {
+		if minLevel != "" {
+			rank, ok := levelRanks[record.Level]
+			if !ok || rank < minRank {
+				continue
+			}
+		}
+		if !hasAttrs(record, attrs) {
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept
+}
+
+func hasAttrs(record types.LogRecord, attrs map[string]string) bool {
+	for key, value := range attrs {
+		actual, ok := record.Attrs[key]
+		if !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// generateLogSummary counts structured records by level, listing the most
+// frequent error and warning messages
+func generateLogSummary(records []types.LogRecord) string {
+	levels := make(map[string]int)
+	errors := make(map[string]int)
+	warnings := make(map[string]int)
+	unstructured := 0
+	for _, record := range records {
+		if record.Unstructured {
+			unstructured++
+			continue
+		}
+		level := record.Level
+		if level == "" {
+			level = "none"
+		}
+		levels[level]++
+
+		switch rank, ok := levelRanks[record.Level]; {
+		case ok && rank >= levelRanks["error"]:
+			errors[record.Message]++
+		case ok && rank == levelRanks["warn"]:
+			warnings[record.Message]++
+		}
+	}
+
+	var names []string
+	for level := range levels {
+		names = append(names, level)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, iok := levelRanks[names[i]]
+		rj, jok := levelRanks[names[j]]
+		if iok != jok {
+			return iok
+		}
+		if ri != rj {
+			return ri > rj
+		}
+		return names[i] < names[j]
+	})
+
+	var counts []string
+	for _, level := range names {
+		counts = append(counts, fmt.Sprintf("%d %s", levels[level], level))
+	}
+	if unstructured > 0 {
+		counts = append(counts, fmt.Sprintf("%d unstructured", unstructured))
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("Log records (%d): %s", len(records), strings.Join(counts, ", ")))
+	writeMessageCounts(&summary, "Errors", errors)
+	writeMessageCounts(&summary, "Warnings", warnings)
+	return summary.String()
+}
+
+// maxSummaryMessages bounds the distinct messages listed per level in a log summary
+const maxSummaryMessages = 5
+
+func writeMessageCounts(summary *strings.Builder, title string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+
+	messages := make([]string, 0, len(counts))
+	for message := range counts {
+		messages = append(messages, message)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		if counts[messages[i]] != counts[messages[j]] {
+			return counts[messages[i]] > counts[messages[j]]
+		}
+		return messages[i] < messages[j]
+	})
+
+	summary.WriteString(fmt.Sprintf("\n%s:\n", title))
+	for i, message := range messages {
+		if i == maxSummaryMessages {
+			summary.WriteString(fmt.Sprintf("  ... %d more messages\n", len(messages)-i))
+			break
+		}
+		summary.WriteString(fmt.Sprintf("  %dx %q\n", counts[message], message))
+	}
+}
@@ -0,0 +1,48 @@
+package debugger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLogLines(t *testing.T) {
+	b := newOutputBuffer(10, maxOutputBytes)
+	b.append("stderr", `{"time":"2024-05-01T10:00:00Z","level":"ERROR","msg":"db timeout","request_id":"abc","http":{"status":500}}`, time.Now())
+	b.append("stderr", `time=2024-05-01T10:00:01Z level=WARN msg="slow query" request_id=abc took=2s`, time.Now())
+	b.append("stdout", `{"level":"info","ts":1714557602.5,"msg":"served","request_id":"def"}`, time.Now())
+	b.append("stdout", "plain text with a=b in it", time.Now())
+
+	records := parseLogLines(b.since(0), "auto")
+	if len(records) != 4 {
+		t.Fatalf("Expected 4 records, got %d", len(records))
+	}
+
+	first := records[0]
+	if first.Level != "error" || first.Message != "db timeout" || first.Time != "2024-05-01T10:00:00Z" ||
+		first.Attrs["request_id"] != "abc" || first.Attrs["http.status"] != "500" {
+		t.Errorf("Unexpected JSON record: %+v", first)
+	}
+	second := records[1]
+	if second.Level != "warn" || second.Message != "slow query" || second.Attrs["took"] != "2s" || second.Source != "stderr" {
+		t.Errorf("Unexpected logfmt record: %+v", second)
+	}
+	if records[2].Time != "1714557602.5" {
+		t.Errorf("Expected the zap timestamp to be kept, got %+v", records[2])
+	}
+	if !records[3].Unstructured || records[3].Message != "plain text with a=b in it" || records[3].LineNumber != 3 {
+		t.Errorf("Expected plain text to stay unstructured, got %+v", records[3])
+	}
+
+	warnings := filterLogRecords(records, "warning", map[string]string{"request_id": "abc"})
+	if len(warnings) != 2 || warnings[0].LineNumber != 0 || warnings[1].LineNumber != 1 {
+		t.Errorf("Expected the two records of request abc at warn or above, got %+v", warnings)
+	}
+
+	summary := generateLogSummary(records)
+	for _, want := range []string{"1 error, 1 warn, 1 info, 1 unstructured", `1x "db timeout"`, `1x "slow query"`} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// ListTargets lists the processes of the session: the launched program and,
+// with follow-exec, the children it spawned
+func (c *Client) ListTargets() types.TargetsResponse {
+	if c.client == nil {
+		return c.createTargetsResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return c.createTargetsResponse(nil, nil, c.exitedError())
+	}
+
+	state, err := c.client.GetState()
+	if err != nil {
+		return c.createTargetsResponse(nil, nil, fmt.Errorf("failed to get state: %v", err))
+	}
+
+	targets, err := c.client.ListTargets()
+	if err != nil {
+		return c.createTargetsResponse(state, nil, fmt.Errorf("failed to list targets: %v", err))
+	}
+	return c.createTargetsResponse(state, targets, nil)
+}
+
+// SwitchTarget selects the process that stepping and evaluation apply to.
+// Breakpoints apply to every target, including children spawned later.
+func (c *Client) SwitchTarget(pid int) types.TargetsResponse {
+	if c.client == nil {
+		return c.createTargetsResponse(nil, nil, fmt.Errorf("no active debug session"))
+	}
+	if c.exited {
+		return c.createTargetsResponse(nil, nil, c.exitedError())
+	}
+
+	targets, err := c.client.ListTargets()
+	if err != nil {
+		return c.createTargetsResponse(nil, nil, fmt.Errorf("failed to list targets: %v", err))
+	}
+
+	for _, target := range targets {
+		if target.Pid != pid {
+			continue
+		}
+		if target.CurrentThread == nil {
+			return c.createTargetsResponse(nil, targets, fmt.Errorf("target %d has no thread to switch to", pid))
+		}
+		state, err := c.client.SwitchThread(target.CurrentThread.ID)
+		if err != nil {
+			return c.createTargetsResponse(nil, targets, fmt.Errorf("failed to switch to target %d: %v", pid, err))
+		}
+		return c.createTargetsResponse(state, targets, nil)
+	}
+
+	return c.createTargetsResponse(nil, targets, fmt.Errorf("no target with PID %d", pid))
+}
+
+// createTargetsResponse creates a response listing the targets of the session
+func (c *Client) createTargetsResponse(state *api.DebuggerState, targets []api.Target, err error) types.TargetsResponse {
+	context := c.createDebugContext(state)
+	context.Operation = "targets"
+
+	response := types.TargetsResponse{
+		Status:  "success",
+		Context: context,
+	}
+	for _, target := range targets {
+		info := types.Target{
+			Pid:     target.Pid,
+			CmdLine: target.CmdLine,
+		}
+		if state != nil {
+			info.Selected = target.Pid == state.Pid
+		}
+		if target.CurrentThread != nil && target.CurrentThread.Function != nil {
+			location := fmt.Sprintf("At %s:%d in %s", target.CurrentThread.File, target.CurrentThread.Line, target.CurrentThread.Function.Name())
+			info.CurrentLocation = &location
+		}
+		response.Targets = append(response.Targets, info)
+	}
+	if err != nil {
+		response.Status = "error"
+		response.Context.ErrorMessage = err.Error()
+		return response
+	}
+
+	response.Summary = fmt.Sprintf("%d targets", len(response.Targets))
+	if state != nil {
+		response.Summary += fmt.Sprintf(", PID %d selected", state.Pid)
+	}
+	return response
+}
@@ -0,0 +1,27 @@
+package debugger
+
+import (
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestCreateTargetsResponse(t *testing.T) {
+	c := NewClient()
+	state := &api.DebuggerState{Pid: 43}
+	targets := []api.Target{
+		{Pid: 42, CmdLine: "/tmp/cli build"},
+		{Pid: 43, CmdLine: "/tmp/worker -id 1", CurrentThread: &api.Thread{File: "/src/worker.go", Line: 9, Function: &api.Function{Name_: "main.main"}}},
+	}
+
+	response := c.createTargetsResponse(state, targets, nil)
+	if len(response.Targets) != 2 || response.Targets[0].Selected || !response.Targets[1].Selected {
+		t.Fatalf("Expected the child to be selected, got %+v", response.Targets)
+	}
+	if loc := response.Targets[1].CurrentLocation; loc == nil || *loc != "At /src/worker.go:9 in main.main" {
+		t.Errorf("Expected the child's location, got %v", loc)
+	}
+	if response.Summary != "2 targets, PID 43 selected" {
+		t.Errorf("Unexpected summary %q", response.Summary)
+	}
+}
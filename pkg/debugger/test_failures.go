@@ -0,0 +1,183 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// testFailureMethods are the testing methods that fail or skip a test. They are
+// defined on testing.common, which T, B and F embed.
+var testFailureMethods = []string{"Fail", "FailNow", "Error", "Errorf", "Fatal", "Fatalf", "Skip", "Skipf", "SkipNow"}
+
+// failureArgsLoadConfig loads the message arguments of a failure call, including
+// the values behind their interface{} wrappers
+var failureArgsLoadConfig = api.LoadConfig{
+	FollowPointers:     true,
+	MaxVariableRecurse: 2,
+	MaxStringLen:       1024,
+	MaxArrayValues:     64,
+	MaxStructFields:    -1,
+}
+
+// setFailureBreakpoints installs a breakpoint on every testing method that fails
+// or skips a test, so execution stops where the test reports a failure
+func (c *Client) setFailureBreakpoints() error {
+	if c.failureBreakpoints == nil {
+		c.failureBreakpoints = make(map[int]string)
+	}
+
+	for _, method := range testFailureMethods {
+		bp, err := c.client.CreateBreakpoint(&api.Breakpoint{
+			FunctionName: "testing.(*common)." + method,
+		})
+		if err != nil {
+			// Methods may be missing when the linker dropped them as unused
+			logger.Debug("Warning: Failed to set breakpoint on testing.(*common).%s: %v", method, err)
+			continue
+		}
+		c.failureBreakpoints[bp.ID] = method
+	}
+
+	if len(c.failureBreakpoints) == 0 {
+		return fmt.Errorf("failed to set breakpoints on the testing failure methods")
+	}
+	return nil
+}
+
+// failureMethod returns the testing method a state is stopped at, if it is one
+// of the failure breakpoints
+func (c *Client) failureMethod(state *api.DebuggerState) (string, bool) {
+	if state == nil || state.Exited || state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil {
+		return "", false
+	}
+	method, ok := c.failureBreakpoints[state.CurrentThread.Breakpoint.ID]
+	return method, ok
+}
+
+// isInternalFailureStop reports whether state is at a failure breakpoint hit
+// from inside package testing, such as Errorf calling Fail. Those are continued
+// past so each failure stops only once, at the call made by the test.
+func (c *Client) isInternalFailureStop(state *api.DebuggerState) bool {
+	if _, ok := c.failureMethod(state); !ok || state.SelectedGoroutine == nil {
+		return false
+	}
+
+	frames, err := c.client.Stacktrace(state.SelectedGoroutine.ID, 2, 0, nil)
+	if err != nil || len(frames) < 2 || !isTestingFunction(frames[1].Function) {
+		return false
+	}
+
+	logger.Debug("Skipping failure breakpoint called from %s", frames[1].Function.Name())
+	return true
+}
+
+// testFailure describes the failure a state is stopped at, or returns nil when
+// it is not stopped at a failure breakpoint
+func (c *Client) testFailure(state *api.DebuggerState) *types.TestFailure {
+	method, ok := c.failureMethod(state)
+	if !ok || state.SelectedGoroutine == nil {
+		return nil
+	}
+
+	failure := &types.TestFailure{
+		Method:  method,
+		Skipped: strings.HasPrefix(method, "Skip"),
+	}
+
+	scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID, Frame: 0}
+	if name, err := c.client.EvalVariable(scope, "c.name", failureArgsLoadConfig); err == nil {
+		failure.TestName = name.Value
+	}
+
+	if strings.HasSuffix(method, "f") {
+		format, err := c.client.EvalVariable(scope, "format", failureArgsLoadConfig)
+		args, argsErr := c.client.EvalVariable(scope, "args", failureArgsLoadConfig)
+		if err == nil && argsErr == nil {
+			failure.Message = fmt.Sprintf(format.Value, failureArgValues(args)...)
+		}
+	} else if method != "Fail" && method != "FailNow" && method != "SkipNow" {
+		if args, err := c.client.EvalVariable(scope, "args", failureArgsLoadConfig); err == nil {
+			failure.Message = strings.TrimSuffix(fmt.Sprintln(failureArgValues(args)...), "\n")
+		}
+	}
+
+	// The caller is the first frame outside package testing, in the test or one of its helpers
+	cfg := failureArgsLoadConfig
+	cfg.MaxVariableRecurse = 1
+	frames, err := c.client.Stacktrace(state.SelectedGoroutine.ID, 20, 0, &cfg)
+	if err != nil {
+		logger.Debug("Warning: Failed to get stack trace of test failure: %v", err)
+		return failure
+	}
+	for _, frame := range frames {
+		if isTestingFunction(frame.Function) {
+			continue
+		}
+
+		failure.File = frame.File
+		failure.Line = frame.Line
+		if frame.Function != nil {
+			failure.Function = frame.Function.Name()
+		}
+		for i := range frame.Arguments {
+			failure.Locals = append(failure.Locals, convertVariable(&frame.Arguments[i], "argument"))
+		}
+		for i := range frame.Locals {
+			failure.Locals = append(failure.Locals, convertVariable(&frame.Locals[i], "local"))
+		}
+		break
+	}
+
+	return failure
+}
+
+// isTestingFunction reports whether fn belongs to package testing
+func isTestingFunction(fn *api.Function) bool {
+	return fn != nil && strings.HasPrefix(fn.Name(), "testing.")
+}
+
+// failureArgValues converts the ...any arguments of a failure call back into Go
+// values, so the message can be formatted the way the testing package would
+func failureArgValues(args *api.Variable) []interface{} {
+	values := make([]interface{}, 0, len(args.Children))
+	for i := range args.Children {
+		arg := &args.Children[i]
+		// Each element is an interface{} whose only child is the dynamic value
+		if arg.Kind == reflect.Interface && len(arg.Children) == 1 {
+			arg = &arg.Children[0]
+		}
+		values = append(values, failureArgValue(arg))
+	}
+	return values
+}
+
+// failureArgValue converts a loaded Delve value into the closest Go value
+func failureArgValue(v *api.Variable) interface{} {
+	switch v.Kind {
+	case reflect.String:
+		return v.Value
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(v.Value); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, err := strconv.ParseUint(v.Value, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(v.Value, 64); err == nil {
+			return f
+		}
+	}
+	return convertVariable(v, "argument").Value
+}
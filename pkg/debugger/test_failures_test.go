@@ -0,0 +1,35 @@
+package debugger
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+func TestFailureArgValues(t *testing.T) {
+	wrap := func(v api.Variable) api.Variable {
+		return api.Variable{Kind: reflect.Interface, Type: "interface {}", Children: []api.Variable{v}}
+	}
+
+	args := &api.Variable{
+		Kind: reflect.Slice,
+		Children: []api.Variable{
+			wrap(api.Variable{Kind: reflect.Int, Type: "int", Value: "42"}),
+			wrap(api.Variable{Kind: reflect.String, Type: "string", Value: "got"}),
+			wrap(api.Variable{Kind: reflect.Float64, Type: "float64", Value: "1.5"}),
+			wrap(api.Variable{Kind: reflect.Bool, Type: "bool", Value: "true"}),
+			wrap(api.Variable{Kind: reflect.Struct, Type: "main.point", Children: []api.Variable{
+				{Name: "X", Kind: reflect.Int, Value: "1"},
+				{Name: "Y", Kind: reflect.Int, Value: "2"},
+			}}),
+		},
+	}
+
+	got := fmt.Sprintf("Add() = %d, %s %.1f %t %v", failureArgValues(args)...)
+	expected := "Add() = 42, got 1.5 true {X:1, Y:2}"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
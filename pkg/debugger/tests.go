@@ -0,0 +1,160 @@
+package debugger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// resolveTestDir returns the directory of the package under test, either from
+// the package pattern or from the directory of the given test file
+func resolveTestDir(target types.TestTarget) (string, error) {
+	if target.Package != "" {
+		pkg, err := resolvePackage(target.Package, target.Dir)
+		if err != nil {
+			return "", err
+		}
+		return pkg.Dir, nil
+	}
+
+	if target.TestFile == "" {
+		return "", fmt.Errorf("either a test file or a package is required")
+	}
+
+	absPath, err := filepath.Abs(target.TestFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path: %v", err)
+	}
+
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("test file not found: %s", absPath)
+	}
+
+	return filepath.Dir(absPath), nil
+}
+
+// testRunPattern converts a test name with an optional subtest path into a
+// -test.run pattern anchored at every level, so TestX/case_3 becomes ^TestX$/^case_3$
+func testRunPattern(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = "^" + regexp.QuoteMeta(part) + "$"
+	}
+	return strings.Join(parts, "/")
+}
+
+// resolveFuzzCase finds a fuzz corpus entry in the package's testdata/fuzz
+// directory. The case is given as FuzzX/file, as testdata/fuzz/FuzzX/file, or
+// as a path to the file; the fuzz target is the name of its directory.
+func resolveFuzzCase(testDir string, fuzzCase string) (string, string, error) {
+	path := filepath.FromSlash(fuzzCase)
+	if !filepath.IsAbs(path) {
+		if strings.HasPrefix(filepath.ToSlash(path), "testdata/fuzz/") {
+			path = filepath.Join(testDir, path)
+		} else {
+			path = filepath.Join(testDir, "testdata", "fuzz", path)
+		}
+	}
+
+	// go test only replays the corpus of the package under test
+	fuzzDir := filepath.Dir(path)
+	fuzzTarget := filepath.Base(fuzzDir)
+	if filepath.Dir(fuzzDir) != filepath.Join(testDir, "testdata", "fuzz") || !isTestFunctionName(fuzzTarget, "Fuzz") {
+		return "", "", fmt.Errorf("fuzz case %s is not in %s", fuzzCase, filepath.Join(testDir, "testdata", "fuzz", "FuzzX"))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("fuzz case not found: %s", path)
+	}
+	if info.IsDir() {
+		return "", "", fmt.Errorf("fuzz case is a directory, expected a corpus file: %s", path)
+	}
+
+	return fuzzTarget, path, nil
+}
+
+// stopAtTestCase continues the test binary until the test or subtest with the
+// given full name (e.g. TestX/case_3) starts, and stops on the first line of its body.
+//
+// Every test and subtest runs through testing.tRunner(t, fn), so a breakpoint
+// on tRunner conditioned on t's name identifies the case, and fn is the body
+// about to run. Parallel subtests sharing the same closure may reach the body
+// first; this is best-effort in that case.
+func (c *Client) stopAtTestCase(name string) (*api.DebuggerState, error) {
+	// Like go test -run, testing names subtests with their spaces replaced
+	runnerBp, err := c.client.CreateBreakpoint(&api.Breakpoint{
+		FunctionName: "testing.tRunner",
+		Cond:         fmt.Sprintf("t.common.name == %q", rewriteSubtestName(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set breakpoint on testing.tRunner: %v", err)
+	}
+
+	logger.Debug("Continuing until test case %s starts", name)
+	state, _, err := c.resume(0)
+	if _, err := c.client.ClearBreakpoint(runnerBp.ID); err != nil {
+		logger.Debug("Warning: Failed to clear tRunner breakpoint: %v", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Exited {
+		return state, fmt.Errorf("test case %s did not run before the process exited", name)
+	}
+	if state.CurrentThread == nil || state.CurrentThread.Breakpoint == nil || state.CurrentThread.Breakpoint.ID != runnerBp.ID {
+		return state, fmt.Errorf("program stopped before test case %s started", name)
+	}
+	if state.SelectedGoroutine == nil {
+		return state, fmt.Errorf("no goroutine selected at test case %s", name)
+	}
+
+	// Delve reports function values by the name of the function they point to
+	scope := api.EvalScope{GoroutineID: state.SelectedGoroutine.ID, Frame: 0}
+	fn, err := c.client.EvalVariable(scope, "fn", api.LoadConfig{})
+	if err != nil {
+		return state, fmt.Errorf("failed to find the body of test case %s: %v", name, err)
+	}
+	if fn.Value == "" || fn.Value == "nil" {
+		return state, fmt.Errorf("test case %s has no body to stop in", name)
+	}
+
+	bodyBp, err := c.client.CreateBreakpoint(&api.Breakpoint{FunctionName: fn.Value})
+	if err != nil {
+		return state, fmt.Errorf("failed to set breakpoint on %s: %v", fn.Value, err)
+	}
+
+	logger.Debug("Continuing into test body %s", fn.Value)
+	state, _, err = c.resume(0)
+	if _, err := c.client.ClearBreakpoint(bodyBp.ID); err != nil {
+		logger.Debug("Warning: Failed to clear test body breakpoint: %v", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Exited {
+		return state, fmt.Errorf("process exited before entering test case %s", name)
+	}
+
+	return state, nil
+}
+
+// rewriteSubtestName rewrites a subtest name the way package testing does,
+// replacing spaces with underscores
+func rewriteSubtestName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
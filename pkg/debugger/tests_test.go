@@ -0,0 +1,68 @@
+package debugger
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTestRunPattern(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "TestAdd", expected: "^TestAdd$"},
+		{name: "TestTable/case_3", expected: "^TestTable$/^case_3$"},
+		{name: "TestTable/a+b/nested(1)", expected: `^TestTable$/^a\+b$/^nested\(1\)$`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := testRunPattern(tc.name)
+			if got != tc.expected {
+				t.Fatalf("Expected %s, got %s", tc.expected, got)
+			}
+
+			// go test matches each slash-separated level against the test name at that level
+			levels := strings.Split(got, "/")
+			for i, part := range strings.Split(tc.name, "/") {
+				if !regexp.MustCompile(levels[i]).MatchString(part) {
+					t.Errorf("Level %d pattern %s does not match %s", i, levels[i], part)
+				}
+				if regexp.MustCompile(levels[i]).MatchString(part + "x") {
+					t.Errorf("Level %d pattern %s is not anchored", i, levels[i])
+				}
+			}
+		})
+	}
+}
+
+func TestResolveFuzzCase(t *testing.T) {
+	testDir := t.TempDir()
+	corpusDir := filepath.Join(testDir, "testdata", "fuzz", "FuzzParse")
+	if err := os.MkdirAll(corpusDir, 0755); err != nil {
+		t.Fatalf("Failed to create corpus directory: %v", err)
+	}
+	corpusFile := filepath.Join(corpusDir, "582528ddfad69eb5")
+	if err := os.WriteFile(corpusFile, []byte("go test fuzz v1\nstring(\"0\")\n"), 0644); err != nil {
+		t.Fatalf("Failed to write corpus file: %v", err)
+	}
+
+	for _, fuzzCase := range []string{"FuzzParse/582528ddfad69eb5", "testdata/fuzz/FuzzParse/582528ddfad69eb5", corpusFile} {
+		fuzzTarget, file, err := resolveFuzzCase(testDir, fuzzCase)
+		if err != nil {
+			t.Fatalf("Failed to resolve %s: %v", fuzzCase, err)
+		}
+		if fuzzTarget != "FuzzParse" || file != corpusFile {
+			t.Errorf("Expected FuzzParse and %s for %s, got %s and %s", corpusFile, fuzzCase, fuzzTarget, file)
+		}
+	}
+
+	for _, fuzzCase := range []string{"FuzzParse/missing", "FuzzParse", filepath.Join(t.TempDir(), "FuzzParse", "582528ddfad69eb5")} {
+		if _, _, err := resolveFuzzCase(testDir, fuzzCase); err == nil {
+			t.Errorf("Expected an error for %s", fuzzCase)
+		}
+	}
+}
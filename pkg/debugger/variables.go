@@ -6,14 +6,24 @@ import (
 	"strings"
 
 	"github.com/go-delve/delve/service/api"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
-// EvalVariable evaluates a variable expression
-func (c *Client) EvalVariable(name string, depth int) types.EvalVariableResponse {
+// EvalVariable evaluates a variable expression. With callUnwrap the wrapping
+// chain of an error is found by calling Unwrap, which runs code in the program.
+func (c *Client) EvalVariable(name string, depth int, callUnwrap bool) types.EvalVariableResponse {
 	if c.client == nil {
 		return c.createEvalVariableResponse(nil, nil, 0, fmt.Errorf("no active debug session"))
 	}
+	if callUnwrap {
+		if err := c.readOnlyError("callUnwrap"); err != nil {
+			return c.createEvalVariableResponse(nil, nil, 0, err)
+		}
+		if c.exited {
+			return c.createEvalVariableResponse(nil, nil, 0, c.exitedError())
+		}
+	}
 
 	// Get current state for context
 	state, err := c.client.GetState()
@@ -46,43 +56,39 @@ func (c *Client) EvalVariable(name string, depth int) types.EvalVariableResponse
 		return c.createEvalVariableResponse(state, nil, 0, fmt.Errorf("failed to evaluate variable %s: %v", name, err))
 	}
 
-	// Convert to our type
-	variable := &types.Variable{
-		DelveVar: v,
-		Name:     v.Name,
-		Type:     v.Type,
-		Kind:     getVariableKind(v),
+	// Convert to our type, then add what only a single expression reports
+	variable := convertVariable(v, "")
+	if _, formatted := formatVariable(v); !formatted && (v.Kind == reflect.Array || v.Kind == reflect.Slice) {
+		// Evaluated elements have always been separated by ", ", locals by ","
+		variable.Value = formatElements(v, ", ")
 	}
-
-	// Format the value based on the variable kind
-	if v.Kind == reflect.Struct {
-		// For struct types, format fields
-		if len(v.Children) > 0 {
-			fields := make([]string, 0, len(v.Children))
-			for _, field := range v.Children {
-				fieldStr := fmt.Sprintf("%s:%s", field.Name, field.Value)
-				fields = append(fields, fieldStr)
-			}
-			variable.Value = "{" + strings.Join(fields, ", ") + "}"
+	if v.Kind == reflect.Chan {
+		// Delve's own value still describes the channel when its internals can't be read
+		channel, err := c.inspectChannel(scope, v, loadConfig)
+		if err != nil {
+			logger.Debug("Warning: Failed to inspect channel %s: %v", name, err)
 		} else {
-			variable.Value = "{}" // Empty struct
+			variable.Channel = channel
+			variable.Value = formatChannel(v.Type, channel)
 		}
-	} else if v.Kind == reflect.Array || v.Kind == reflect.Slice {
-		// For array or slice types, format elements
-		if len(v.Children) > 0 {
-			elements := make([]string, 0, len(v.Children))
-			for _, element := range v.Children {
-				elements = append(elements, element.Value)
+	}
+
+	if v.Kind == reflect.Interface && v.Type == "error" && !isNilInterface(v) {
+		chain, err := c.errorChain(scope, name, callUnwrap)
+		if err != nil && callUnwrap {
+			// Calls may have left the program elsewhere, so report where it is now
+			if current, stateErr := c.client.GetState(); stateErr == nil {
+				state = current
 			}
-			variable.Value = "[" + strings.Join(elements, ", ") + "]"
-		} else {
-			variable.Value = "[]" // Empty array or slice
+			return c.createEvalVariableResponse(state, nil, 0, fmt.Errorf("failed to unwrap error %s: %v", name, err))
 		}
-	} else {
-		variable.Value = v.Value
+		if err != nil {
+			logger.Debug("Warning: Failed to unwrap error %s: %v", name, err)
+		}
+		variable.ErrorChain = chain
 	}
 
-	return c.createEvalVariableResponse(state, variable, depth, nil)
+	return c.createEvalVariableResponse(state, &variable, depth, nil)
 }
 
 // Helper functions for variable information
@@ -170,48 +176,6 @@ func (c *Client) getLocalVariables(state *api.DebuggerState) ([]types.Variable,
 		MaxStructFields:    -1,
 	}
 
-	// Convert Delve variables to our format
-	convertToVariable := func(v *api.Variable, scope string) types.Variable {
-		var value string
-
-		// Format the value based on the variable kind
-		if v.Kind == reflect.Struct {
-			// For struct types, format fields
-			if len(v.Children) > 0 {
-				fields := make([]string, 0, len(v.Children))
-				for _, field := range v.Children {
-					fieldStr := fmt.Sprintf("%s:%s", field.Name, field.Value)
-					fields = append(fields, fieldStr)
-				}
-				value = "{" + strings.Join(fields, ", ") + "}"
-			} else {
-				value = "{}" // Empty struct
-			}
-		} else if v.Kind == reflect.Array || v.Kind == reflect.Slice {
-			// For array or slice types, format elements
-			if len(v.Children) > 0 {
-				elements := make([]string, 0, len(v.Children))
-				for _, element := range v.Children {
-					elements = append(elements, element.Value)
-				}
-				value = "[" + strings.Join(elements, ",") + "]"
-			} else {
-				value = "[]" // Empty array or slice
-			}
-		} else {
-			value = v.Value
-		}
-
-		return types.Variable{
-			DelveVar: v,
-			Name:     v.Name,
-			Value:    value,
-			Type:     v.Type,
-			Scope:    scope,
-			Kind:     getVariableKind(v),
-		}
-	}
-
 	var variables []types.Variable
 
 	// Get function arguments
@@ -228,17 +192,64 @@ func (c *Client) getLocalVariables(state *api.DebuggerState) ([]types.Variable,
 
 	// Process arguments first
 	for _, arg := range args {
-		variables = append(variables, convertToVariable(&arg, "argument"))
+		variables = append(variables, convertVariable(&arg, "argument"))
 	}
 
 	// Process local variables
 	for _, local := range locals {
-		variables = append(variables, convertToVariable(&local, "local"))
+		variables = append(variables, convertVariable(&local, "local"))
 	}
 
 	return variables, nil
 }
 
+// convertVariable converts a Delve variable to our format
+func convertVariable(v *api.Variable, scope string) types.Variable {
+	var value string
+
+	// Format the value based on the variable kind
+	if formatted, ok := formatVariable(v); ok {
+		value = formatted
+	} else if v.Kind == reflect.Struct {
+		// For struct types, format fields
+		if len(v.Children) > 0 {
+			fields := make([]string, 0, len(v.Children))
+			for i := range v.Children {
+				fieldStr := fmt.Sprintf("%s:%s", v.Children[i].Name, variableValue(&v.Children[i]))
+				fields = append(fields, fieldStr)
+			}
+			value = "{" + strings.Join(fields, ", ") + "}"
+		} else {
+			value = "{}" // Empty struct
+		}
+	} else if v.Kind == reflect.Array || v.Kind == reflect.Slice {
+		value = formatElements(v, ",")
+	} else {
+		value = v.Value
+	}
+
+	return types.Variable{
+		DelveVar: v,
+		Name:     v.Name,
+		Value:    value,
+		Type:     v.Type,
+		Scope:    scope,
+		Kind:     getVariableKind(v),
+	}
+}
+
+// formatElements formats the elements of an array or slice, joined by sep
+func formatElements(v *api.Variable, sep string) string {
+	if len(v.Children) == 0 {
+		return "[]" // Empty array or slice
+	}
+	elements := make([]string, 0, len(v.Children))
+	for i := range v.Children {
+		elements = append(elements, variableValue(&v.Children[i]))
+	}
+	return "[" + strings.Join(elements, sep) + "]"
+}
+
 // createEvalVariableResponse creates an EvalVariableResponse
 func (c *Client) createEvalVariableResponse(state *api.DebuggerState, variable *types.Variable, depth int, err error) types.EvalVariableResponse {
 	context := c.createDebugContext(state)
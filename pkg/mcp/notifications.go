@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/sunfmin/mcp-go-debugger/pkg/debugger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// streamOutput forwards the program output of client to the MCP client that
+// made the request, as notifications/message log notifications tagged with the
+// session, the stream (stdout or stderr) and the capture time
+func (s *MCPDebugServer) streamOutput(ctx context.Context, client *debugger.Client) {
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return
+	}
+
+	// The request context ends with the tool call; notifications outlive it
+	notifyCtx := s.server.WithContext(context.Background(), session)
+
+	client.SetOutputHandler(func(msg debugger.OutputMessage) {
+		level := mcp.LoggingLevelInfo
+		if msg.Source == "stderr" {
+			level = mcp.LoggingLevelWarning
+		}
+
+		params := map[string]any{
+			"level":  level,
+			"logger": msg.Source,
+			"data": map[string]any{
+				"session":   s.sessionIDOf(client),
+				"source":    msg.Source,
+				"line":      msg.Content,
+				"timestamp": msg.Timestamp.Format(time.RFC3339Nano),
+			},
+		}
+
+		// Sending never blocks; a full notification queue drops the line, which stays in the output buffers
+		if err := s.server.SendNotificationToClient(notifyCtx, "notifications/message", params); err != nil {
+			logger.Debug("Failed to send output notification: %v", err)
+		}
+	})
+}
+
+// progressReporter returns a function sending notifications/progress for a
+// core dump, or nil when the request did not ask for progress
+func (s *MCPDebugServer) progressReporter(ctx context.Context, request mcp.CallToolRequest) func(types.DumpProgress) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	token := request.Params.Meta.ProgressToken
+
+	return func(progress types.DumpProgress) {
+		params := map[string]any{
+			"progressToken": token,
+			"progress":      float64(progress.MemDone),
+			"total":         float64(progress.MemTotal),
+		}
+		if err := s.server.SendNotificationToClient(ctx, "notifications/progress", params); err != nil {
+			logger.Debug("Failed to send progress notification: %v", err)
+		}
+	}
+}
@@ -4,24 +4,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/sunfmin/mcp-go-debugger/pkg/debugger"
 	"github.com/sunfmin/mcp-go-debugger/pkg/logger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
 type MCPDebugServer struct {
-	server      *server.MCPServer
-	debugClient *debugger.Client
-	version     string
+	server         *server.MCPServer
+	version        string
+	sessionsMu     sync.Mutex
+	sessions       map[string]*debugger.Client
+	sessionOrder   []string // Session IDs in start order
+	currentSession string   // Session used when a tool call doesn't name one
+	nextSessionID  int
 }
 
 func NewMCPDebugServer(version string) *MCPDebugServer {
 	s := &MCPDebugServer{
-		server:      server.NewMCPServer("Go Debugger MCP", version),
-		debugClient: debugger.NewClient(),
-		version:     version,
+		server:   server.NewMCPServer("Go Debugger MCP", version, server.WithLogging()),
+		version:  version,
+		sessions: make(map[string]*debugger.Client),
 	}
 
 	s.registerTools()
@@ -33,15 +40,25 @@ func (s *MCPDebugServer) Server() *server.MCPServer {
 	return s.server
 }
 
+// DebugClient returns the client of the current session
 func (s *MCPDebugServer) DebugClient() *debugger.Client {
-	return s.debugClient
+	_, client, _ := s.lookupSession(mcp.CallToolRequest{})
+	return client
 }
 
 func (s *MCPDebugServer) registerTools() {
 	s.addDebugSourceFileTool()
+	s.addDebugPackageTool()
 	s.addDebugTestTool()
+	s.addDebugBenchmarkTool()
+	s.addDebugFuzzCaseTool()
+	s.addListTestsTool()
 	s.addLaunchTool()
 	s.addAttachTool()
+	s.addConnectTool()
+	s.addOpenCoreTool()
+	s.addDumpCoreTool()
+	s.addListProcessesTool()
 	s.addCloseTool()
 	s.addSetBreakpointTool()
 	s.addListBreakpointsTool()
@@ -51,11 +68,22 @@ func (s *MCPDebugServer) registerTools() {
 	s.addStepOverTool()
 	s.addStepOutTool()
 	s.addEvalVariableTool()
+	s.addGoroutinesTool()
+	s.addStacktraceTool()
+	s.addAnalyzeGoroutinesTool()
+	s.addInspectMutexTool()
+	s.addSnapshotGoroutinesTool()
+	s.addDiffGoroutineSnapshotsTool()
 	s.addGetDebuggerOutputTool()
+	s.addWriteStdinTool()
+	s.addBreakOnOutputTool()
+	s.addListTargetsTool()
+	s.addSwitchTargetTool()
+	s.addListSessionsTool()
 }
 
 func (s *MCPDebugServer) addLaunchTool() {
-	launchTool := mcp.NewTool("launch",
+	options := []mcp.ToolOption{
 		mcp.WithDescription("Launch a Go application with debugging enabled"),
 		mcp.WithString("program",
 			mcp.Required(),
@@ -64,26 +92,86 @@ func (s *MCPDebugServer) addLaunchTool() {
 		mcp.WithArray("args",
 			mcp.Description("Arguments to pass to the program"),
 		),
-	)
+	}
+	options = append(options, runOptionArgs()...)
+	launchTool := mcp.NewTool("launch", options...)
 
 	s.server.AddTool(launchTool, s.Launch)
 }
 
 func (s *MCPDebugServer) addAttachTool() {
 	attachTool := mcp.NewTool("attach",
-		mcp.WithDescription("Attach to a running Go process"),
+		mcp.WithDescription("Attach to a running Go process by PID, name or command-line pattern"),
 		mcp.WithNumber("pid",
-			mcp.Required(),
 			mcp.Description("Process ID to attach to"),
 		),
+		mcp.WithString("process",
+			mcp.Description("Executable name, or a regular expression over the command line, matching exactly one Go process; see list_processes"),
+		),
 	)
 
 	s.server.AddTool(attachTool, s.Attach)
 }
 
+func (s *MCPDebugServer) addConnectTool() {
+	connectTool := mcp.NewTool("connect",
+		mcp.WithDescription("Connect to a Delve server that is already running, such as dlv --headless --listen=:2345 --accept-multiclient"),
+		mcp.WithString("address",
+			mcp.Required(),
+			mcp.Description("host:port the Delve server listens on"),
+		),
+	)
+
+	s.server.AddTool(connectTool, s.Connect)
+}
+
+func (s *MCPDebugServer) addOpenCoreTool() {
+	openCoreTool := mcp.NewTool("open_core",
+		mcp.WithDescription("Open a core dump with the executable that produced it for read-only post-mortem inspection with stacktrace, goroutines and eval_variable"),
+		mcp.WithString("core",
+			mcp.Required(),
+			mcp.Description("Path to the core file, e.g. from a crash with GOTRACEBACK=crash"),
+		),
+		mcp.WithString("executable",
+			mcp.Required(),
+			mcp.Description("Path to the exact binary that produced the core"),
+		),
+	)
+
+	s.server.AddTool(openCoreTool, s.OpenCore)
+}
+
+func (s *MCPDebugServer) addDumpCoreTool() {
+	dumpCoreTool := mcp.NewTool("dump_core",
+		mcp.WithDescription("Write a core file of the stopped program to analyse later with open_core, reporting progress while writing"),
+		withSessionArg(),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("Where to write the core file; it must not exist. The executable is kept next to it as <path>.bin when the session built it. For connect sessions this is an absolute path on the Delve server's host, where the core and the executable stay"),
+		),
+	)
+
+	s.server.AddTool(dumpCoreTool, s.DumpCore)
+}
+
+func (s *MCPDebugServer) addListProcessesTool() {
+	listProcessesTool := mcp.NewTool("list_processes",
+		mcp.WithDescription("List running Go processes that can be attached to, with their command line and Go version"),
+		mcp.WithString("filter",
+			mcp.Description("Regular expression the executable name or command line must match"),
+		),
+	)
+
+	s.server.AddTool(listProcessesTool, s.ListProcesses)
+}
+
 func (s *MCPDebugServer) addCloseTool() {
 	closeTool := mcp.NewTool("close",
 		mcp.WithDescription("Close the current debugging session"),
+		withSessionArg(),
+		mcp.WithBoolean("kill",
+			mcp.Description("Kill the program instead of detaching from it; defaults to true, except for sessions opened with connect. Only attach and connect sessions can leave the program running"),
+		),
 	)
 
 	s.server.AddTool(closeTool, s.Close)
@@ -92,6 +180,7 @@ func (s *MCPDebugServer) addCloseTool() {
 func (s *MCPDebugServer) addSetBreakpointTool() {
 	breakpointTool := mcp.NewTool("set_breakpoint",
 		mcp.WithDescription("Set a breakpoint at a specific file location"),
+		withSessionArg(),
 		mcp.WithString("file",
 			mcp.Required(),
 			mcp.Description("Path to the file"),
@@ -108,6 +197,7 @@ func (s *MCPDebugServer) addSetBreakpointTool() {
 func (s *MCPDebugServer) addListBreakpointsTool() {
 	listBreakpointsTool := mcp.NewTool("list_breakpoints",
 		mcp.WithDescription("List all currently set breakpoints"),
+		withSessionArg(),
 	)
 
 	s.server.AddTool(listBreakpointsTool, s.ListBreakpoints)
@@ -116,6 +206,7 @@ func (s *MCPDebugServer) addListBreakpointsTool() {
 func (s *MCPDebugServer) addRemoveBreakpointTool() {
 	removeBreakpointTool := mcp.NewTool("remove_breakpoint",
 		mcp.WithDescription("Remove a breakpoint by its ID"),
+		withSessionArg(),
 		mcp.WithNumber("id",
 			mcp.Required(),
 			mcp.Description("ID of the breakpoint to remove"),
@@ -126,7 +217,7 @@ func (s *MCPDebugServer) addRemoveBreakpointTool() {
 }
 
 func (s *MCPDebugServer) addDebugSourceFileTool() {
-	debugTool := mcp.NewTool("debug",
+	options := []mcp.ToolOption{
 		mcp.WithDescription("Debug a Go source file directly"),
 		mcp.WithString("file",
 			mcp.Required(),
@@ -135,33 +226,120 @@ func (s *MCPDebugServer) addDebugSourceFileTool() {
 		mcp.WithArray("args",
 			mcp.Description("Arguments to pass to the program"),
 		),
-	)
+	}
+	options = append(options, buildOptionArgs()...)
+	debugTool := mcp.NewTool("debug", options...)
 
 	s.server.AddTool(debugTool, s.DebugSourceFile)
 }
 
-func (s *MCPDebugServer) addDebugTestTool() {
-	debugTestTool := mcp.NewTool("debug_test",
-		mcp.WithDescription("Debug a Go test function"),
-		mcp.WithString("testfile",
+func (s *MCPDebugServer) addDebugPackageTool() {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Debug a Go main package, including packages split across several files or using internal packages"),
+		mcp.WithString("package",
 			mcp.Required(),
-			mcp.Description("Absolute Path to the test file"),
+			mcp.Description("Package directory (e.g. ./cmd/server) or import path of the main package"),
 		),
+		mcp.WithString("dir",
+			mcp.Description("Directory to resolve the package from; relative package paths and module lookup start here (default: current directory)"),
+		),
+		mcp.WithArray("args",
+			mcp.Description("Arguments to pass to the program"),
+		),
+	}
+	options = append(options, buildOptionArgs()...)
+	debugPackageTool := mcp.NewTool("debug_package", options...)
+
+	s.server.AddTool(debugPackageTool, s.DebugPackage)
+}
+
+func (s *MCPDebugServer) addDebugTestTool() {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Debug Go tests, selected by test file or package pattern, including individual subtests"),
+	}
+	options = append(options, testTargetArgs()...)
+	options = append(options,
 		mcp.WithString("testname",
-			mcp.Required(),
-			mcp.Description("Name of the test function to debug"),
+			mcp.Description("Name of the test to debug; subtests are selected with a path like TestX/case_3, each level matched exactly"),
+		),
+		mcp.WithString("run",
+			mcp.Description("Raw -test.run regular expression, used unmodified instead of testname"),
+		),
+		mcp.WithBoolean("stopAtCase",
+			mcp.Description("Stop automatically at the start of the test or t.Run body named by testname"),
+		),
+		mcp.WithBoolean("stopOnFailure",
+			mcp.Description("Stop where the test calls t.Error(f), t.Fatal(f), t.Fail(Now) or t.Skip(f), reporting the message, the calling frame and its locals; a test still running after a minute is halted"),
 		),
 		mcp.WithArray("testflags",
 			mcp.Description("Optional flags to pass to go test"),
 		),
 	)
+	options = append(options, buildOptionArgs()...)
+	debugTestTool := mcp.NewTool("debug_test", options...)
 
 	s.server.AddTool(debugTestTool, s.DebugTest)
 }
 
+func (s *MCPDebugServer) addDebugBenchmarkTool() {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Debug a Go benchmark by running its body exactly once (-test.bench with -test.benchtime=1x, no tests)"),
+	}
+	options = append(options, testTargetArgs()...)
+	options = append(options,
+		mcp.WithString("benchmark",
+			mcp.Required(),
+			mcp.Description("Name of the benchmark; sub-benchmarks are selected with a path like BenchmarkX/small, each level matched exactly"),
+		),
+		mcp.WithArray("testflags",
+			mcp.Description("Optional flags to pass to the test binary"),
+		),
+	)
+	options = append(options, buildOptionArgs()...)
+	debugBenchmarkTool := mcp.NewTool("debug_benchmark", options...)
+
+	s.server.AddTool(debugBenchmarkTool, s.DebugBenchmark)
+}
+
+func (s *MCPDebugServer) addDebugFuzzCaseTool() {
+	options := []mcp.ToolOption{
+		mcp.WithDescription("Debug a fuzz target with one input from its testdata/fuzz/FuzzX/ corpus, such as the entry saved for a crash"),
+	}
+	options = append(options, testTargetArgs()...)
+	options = append(options,
+		mcp.WithString("fuzzcase",
+			mcp.Required(),
+			mcp.Description("Corpus entry as FuzzX/file, testdata/fuzz/FuzzX/file or a path to the file"),
+		),
+		mcp.WithArray("testflags",
+			mcp.Description("Optional flags to pass to the test binary"),
+		),
+	)
+	options = append(options, buildOptionArgs()...)
+	debugFuzzCaseTool := mcp.NewTool("debug_fuzz_case", options...)
+
+	s.server.AddTool(debugFuzzCaseTool, s.DebugFuzzCase)
+}
+
+func (s *MCPDebugServer) addListTestsTool() {
+	listTestsTool := mcp.NewTool("list_tests",
+		mcp.WithDescription("List the tests, benchmarks, fuzz targets and examples of a package with file:line, including statically known subtest names"),
+		mcp.WithString("package",
+			mcp.Required(),
+			mcp.Description("Package directory (e.g. ./pkg/store) or import path"),
+		),
+		mcp.WithString("dir",
+			mcp.Description("Directory to resolve the package from (default: current directory)"),
+		),
+	)
+
+	s.server.AddTool(listTestsTool, s.ListTests)
+}
+
 func (s *MCPDebugServer) addContinueTool() {
 	continueTool := mcp.NewTool("continue",
 		mcp.WithDescription("Continue execution until next breakpoint or program end"),
+		withSessionArg(),
 	)
 
 	s.server.AddTool(continueTool, s.Continue)
@@ -170,6 +348,7 @@ func (s *MCPDebugServer) addContinueTool() {
 func (s *MCPDebugServer) addStepTool() {
 	stepTool := mcp.NewTool("step",
 		mcp.WithDescription("Step into the next function call"),
+		withSessionArg(),
 	)
 
 	s.server.AddTool(stepTool, s.Step)
@@ -178,6 +357,7 @@ func (s *MCPDebugServer) addStepTool() {
 func (s *MCPDebugServer) addStepOverTool() {
 	stepOverTool := mcp.NewTool("step_over",
 		mcp.WithDescription("Step over the next function call"),
+		withSessionArg(),
 	)
 
 	s.server.AddTool(stepOverTool, s.StepOver)
@@ -186,6 +366,7 @@ func (s *MCPDebugServer) addStepOverTool() {
 func (s *MCPDebugServer) addStepOutTool() {
 	stepOutTool := mcp.NewTool("step_out",
 		mcp.WithDescription("Step out of the current function"),
+		withSessionArg(),
 	)
 
 	s.server.AddTool(stepOutTool, s.StepOut)
@@ -193,7 +374,8 @@ func (s *MCPDebugServer) addStepOutTool() {
 
 func (s *MCPDebugServer) addEvalVariableTool() {
 	evalVarTool := mcp.NewTool("eval_variable",
-		mcp.WithDescription("Evaluate the value of a variable"),
+		mcp.WithDescription("Evaluate the value of a variable; channels show their capacity, buffered values, closed state and the goroutines waiting on them, and errors show the errors they wrap, found through fields of type error or []error or, with callUnwrap, by calling Unwrap"),
+		withSessionArg(),
 		mcp.WithString("name",
 			mcp.Required(),
 			mcp.Description("Name of the variable to evaluate"),
@@ -201,19 +383,330 @@ func (s *MCPDebugServer) addEvalVariableTool() {
 		mcp.WithNumber("depth",
 			mcp.Description("Depth for evaluate nested structures (default: 1)"),
 		),
+		mcp.WithBoolean("callUnwrap",
+			mcp.Description("Find the errors an error wraps by calling its Unwrap method in the program, which also finds errors Unwrap computes. This runs program code on the selected goroutine; if it hits a breakpoint the program stays stopped inside the call and the evaluation fails. Not available for core dumps"),
+		),
 	)
 
 	s.server.AddTool(evalVarTool, s.EvalVariable)
 }
 
+func (s *MCPDebugServer) addGoroutinesTool() {
+	goroutinesTool := mcp.NewTool("goroutines",
+		mcp.WithDescription("List all goroutines with their status, wait reason and location"),
+		withSessionArg(),
+	)
+
+	s.server.AddTool(goroutinesTool, s.Goroutines)
+}
+
+func (s *MCPDebugServer) addStacktraceTool() {
+	stacktraceTool := mcp.NewTool("stacktrace",
+		mcp.WithDescription("Get the call stack of a goroutine, optionally with the arguments and locals of every frame"),
+		withSessionArg(),
+		mcp.WithNumber("goroutine",
+			mcp.Description("Goroutine ID; defaults to the goroutine the program is stopped in"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Maximum number of frames (default 50)"),
+		),
+		mcp.WithBoolean("locals",
+			mcp.Description("Include the arguments and local variables of each frame"),
+		),
+	)
+
+	s.server.AddTool(stacktraceTool, s.Stacktrace)
+}
+
+func (s *MCPDebugServer) addAnalyzeGoroutinesTool() {
+	analyzeTool := mcp.NewTool("analyze_goroutines",
+		mcp.WithDescription("Find deadlocks and leaks: group blocked goroutines by wait reason and location, detect goroutines waiting on each other's channels or mutexes, and flag long-blocked goroutines"),
+		withSessionArg(),
+		mcp.WithString("minBlocked",
+			mcp.Description("Flag goroutines blocked at least this long, as a Go duration (default 1m)"),
+		),
+	)
+
+	s.server.AddTool(analyzeTool, s.AnalyzeGoroutines)
+}
+
+func (s *MCPDebugServer) addInspectMutexTool() {
+	inspectTool := mcp.NewTool("inspect_mutex",
+		mcp.WithDescription("Decode a sync.Mutex or sync.RWMutex (locked, starving, waiter count, readers), list the goroutines waiting on it and the goroutines that may hold it"),
+		withSessionArg(),
+		mcp.WithString("expression",
+			mcp.Description("Expression for the mutex or a pointer to it, such as s.mu; when omitted, every mutex a blocked goroutine waits on is inspected"),
+		),
+	)
+
+	s.server.AddTool(inspectTool, s.InspectMutex)
+}
+
+func (s *MCPDebugServer) addSnapshotGoroutinesTool() {
+	snapshotTool := mcp.NewTool("snapshot_goroutines",
+		mcp.WithDescription("Record the current goroutines, keyed by creation site and stack, under a name for diff_goroutine_snapshots"),
+		withSessionArg(),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Snapshot name, such as before or after; an existing snapshot with this name is replaced"),
+		),
+	)
+
+	s.server.AddTool(snapshotTool, s.SnapshotGoroutines)
+}
+
+func (s *MCPDebugServer) addDiffGoroutineSnapshotsTool() {
+	diffTool := mcp.NewTool("diff_goroutine_snapshots",
+		mcp.WithDescription("Report goroutines that appeared or disappeared between two snapshots, grouped by creation site, to find leaks"),
+		withSessionArg(),
+		mcp.WithString("from",
+			mcp.Required(),
+			mcp.Description("Name of the earlier snapshot"),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("Name of the later snapshot"),
+		),
+	)
+
+	s.server.AddTool(diffTool, s.DiffGoroutineSnapshots)
+}
+
 func (s *MCPDebugServer) addGetDebuggerOutputTool() {
 	outputTool := mcp.NewTool("get_debugger_output",
-		mcp.WithDescription("Get captured stdout and stderr from the debugged program"),
+		mcp.WithDescription("Get captured stdout and stderr from the debugged program, optionally only new, matching or trailing lines"),
+		withSessionArg(),
+		mcp.WithNumber("since",
+			mcp.Description("Only return lines numbered since or later; pass the nextCursor of a previous read"),
+		),
+		mcp.WithBoolean("sinceLastRead",
+			mcp.Description("Only return lines captured after the previous get_debugger_output call"),
+		),
+		mcp.WithNumber("tail",
+			mcp.Description("Only return the last N selected lines"),
+		),
+		mcp.WithString("filter",
+			mcp.Description("Regular expression lines must match"),
+		),
+		mcp.WithString("source",
+			mcp.Description("Only return lines from this stream"),
+			mcp.Enum("stdout", "stderr"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Parse lines as structured logs (slog, zap, logrus) and return records with level, message, time and attributes; auto detects JSON and logfmt per line"),
+			mcp.Enum("json", "logfmt", "auto"),
+		),
+		mcp.WithString("level",
+			mcp.Description("Only return records at this level or above, such as warn or error; requires format"),
+		),
+		mcp.WithObject("attrs",
+			mcp.Description("Only return records with these attribute values, such as {\"request_id\": \"abc\"}; nested groups use dotted keys; requires format"),
+		),
 	)
 
 	s.server.AddTool(outputTool, s.GetDebuggerOutput)
 }
 
+func (s *MCPDebugServer) addWriteStdinTool() {
+	writeStdinTool := mcp.NewTool("write_stdin",
+		mcp.WithDescription("Write to the standard input of a program launched with stdinPipe, optionally closing it to signal EOF"),
+		withSessionArg(),
+		mcp.WithString("data",
+			mcp.Description("Text to write; include a trailing newline for line-based input"),
+		),
+		mcp.WithBoolean("close",
+			mcp.Description("Close standard input after writing, so the program reads EOF"),
+		),
+	)
+
+	s.server.AddTool(writeStdinTool, s.WriteStdin)
+}
+
+func (s *MCPDebugServer) addBreakOnOutputTool() {
+	breakOnOutputTool := mcp.NewTool("break_on_output",
+		mcp.WithDescription("Halt the program when it prints a line matching a regular expression; continue reports the line and the goroutines writing output"),
+		withSessionArg(),
+		mcp.WithString("pattern",
+			mcp.Description("Regular expression matched against each output line"),
+		),
+		mcp.WithString("source",
+			mcp.Description("Only match lines from this stream; both when omitted"),
+			mcp.Enum("stdout", "stderr"),
+		),
+		mcp.WithNumber("remove",
+			mcp.Description("ID of an output breakpoint to remove instead of adding one; 0 removes all"),
+		),
+	)
+
+	s.server.AddTool(breakOnOutputTool, s.BreakOnOutput)
+}
+
+func (s *MCPDebugServer) addListTargetsTool() {
+	listTargetsTool := mcp.NewTool("list_targets",
+		mcp.WithDescription("List the processes of a session: the program and, with followExec, the child processes it executed"),
+		withSessionArg(),
+	)
+
+	s.server.AddTool(listTargetsTool, s.ListTargets)
+}
+
+func (s *MCPDebugServer) addSwitchTargetTool() {
+	switchTargetTool := mcp.NewTool("switch_target",
+		mcp.WithDescription("Select the process that stepping and evaluation apply to; breakpoints apply to all targets"),
+		withSessionArg(),
+		mcp.WithNumber("pid",
+			mcp.Required(),
+			mcp.Description("PID of a target from list_targets"),
+		),
+	)
+
+	s.server.AddTool(switchTargetTool, s.SwitchTarget)
+}
+
+func (s *MCPDebugServer) addListSessionsTool() {
+	listSessionsTool := mcp.NewTool("list_sessions",
+		mcp.WithDescription("List all debug sessions with their target, PID, state and uptime"),
+	)
+
+	s.server.AddTool(listSessionsTool, s.ListSessions)
+}
+
+// runOptionArgs describes the arguments controlling how a debuggee is started
+func runOptionArgs() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("cwd",
+			mcp.Description("Working directory of the program"),
+		),
+		mcp.WithObject("env",
+			mcp.Description("Environment variables to add or override, as a name to value map (Linux)"),
+		),
+		mcp.WithArray("unsetEnv",
+			mcp.Description("Names of environment variables to remove (Linux)"),
+		),
+		mcp.WithBoolean("clearEnv",
+			mcp.Description("Start from an empty environment instead of inheriting the debugger's (Linux)"),
+		),
+		mcp.WithString("stdin",
+			mcp.Description("File to use as the program's standard input"),
+		),
+		mcp.WithString("stdinText",
+			mcp.Description("Inline standard input; the program reads EOF after it unless stdinPipe is set"),
+		),
+		mcp.WithBoolean("stdinPipe",
+			mcp.Description("Keep standard input open so write_stdin can send more input"),
+		),
+		mcp.WithBoolean("followExec",
+			mcp.Description("Also debug child processes the program executes (Linux only); see list_targets"),
+		),
+		mcp.WithString("followExecRegex",
+			mcp.Description("Only follow children whose command line matches this regular expression; implies followExec"),
+		),
+	}
+}
+
+// buildOptionArgs describes the run arguments plus the arguments controlling the debug build
+func buildOptionArgs() []mcp.ToolOption {
+	return append(runOptionArgs(),
+		mcp.WithArray("buildFlags",
+			mcp.Description("Additional flags to pass to go build"),
+		),
+		mcp.WithArray("tags",
+			mcp.Description("Build tags to enable"),
+		),
+		mcp.WithBoolean("race",
+			mcp.Description("Build with the race detector"),
+		),
+	)
+}
+
+// testTargetArgs describes the arguments selecting the package under test
+func testTargetArgs() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("testfile",
+			mcp.Description("Absolute Path to a test file; its directory is the package under test"),
+		),
+		mcp.WithString("package",
+			mcp.Description("Package directory (e.g. ./pkg/store) or import path to test, instead of testfile"),
+		),
+		mcp.WithString("dir",
+			mcp.Description("Directory to resolve the package from (default: current directory)"),
+		),
+	}
+}
+
+// parseTestTarget extracts the package under test from a request
+func parseTestTarget(request mcp.CallToolRequest) types.TestTarget {
+	target := types.TestTarget{}
+	if testfile, ok := request.Params.Arguments["testfile"].(string); ok {
+		target.TestFile = testfile
+	}
+	if pkg, ok := request.Params.Arguments["package"].(string); ok {
+		target.Package = pkg
+	}
+	if dir, ok := request.Params.Arguments["dir"].(string); ok {
+		target.Dir = dir
+	}
+	return target
+}
+
+// parseLaunchOptions extracts launch and build settings from a request
+func parseLaunchOptions(request mcp.CallToolRequest) types.LaunchOptions {
+	arguments := request.Params.Arguments
+
+	opts := types.LaunchOptions{
+		UnsetEnv:   getStringArrayArg(arguments, "unsetEnv"),
+		BuildFlags: getStringArrayArg(arguments, "buildFlags"),
+		Tags:       getStringArrayArg(arguments, "tags"),
+	}
+
+	if cwd, ok := arguments["cwd"].(string); ok {
+		opts.Cwd = cwd
+	}
+	if clearEnv, ok := arguments["clearEnv"].(bool); ok {
+		opts.ClearEnv = clearEnv
+	}
+	if stdin, ok := arguments["stdin"].(string); ok {
+		opts.Stdin = stdin
+	}
+	if stdinText, ok := arguments["stdinText"].(string); ok {
+		opts.StdinText = stdinText
+	}
+	if stdinPipe, ok := arguments["stdinPipe"].(bool); ok {
+		opts.StdinPipe = stdinPipe
+	}
+	if followExec, ok := arguments["followExec"].(bool); ok {
+		opts.FollowExec = followExec
+	}
+	if followExecRegex, ok := arguments["followExecRegex"].(string); ok {
+		opts.FollowExecRegex = followExecRegex
+	}
+	if race, ok := arguments["race"].(bool); ok {
+		opts.Race = race
+	}
+	if env, ok := arguments["env"].(map[string]interface{}); ok {
+		opts.Env = make(map[string]string, len(env))
+		for key, value := range env {
+			opts.Env[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	return opts
+}
+
+// getStringArrayArg returns an optional array argument as strings
+func getStringArrayArg(arguments map[string]interface{}, name string) []string {
+	values, ok := arguments[name].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = fmt.Sprintf("%v", value)
+	}
+	return result
+}
+
 func newErrorResult(format string, args ...interface{}) *mcp.CallToolResult {
 	result := mcp.NewToolResultText(fmt.Sprintf("Error: "+format, args...))
 	result.IsError = true
@@ -234,7 +727,12 @@ func (s *MCPDebugServer) Launch(ctx context.Context, request mcp.CallToolRequest
 		}
 	}
 
-	response := s.debugClient.LaunchProgram(program, args)
+	client := debugger.NewClient()
+	s.streamOutput(ctx, client)
+	response := client.LaunchProgram(program, args, parseLaunchOptions(request))
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
+	}
 
 	return newToolResultJSON(response)
 }
@@ -242,10 +740,69 @@ func (s *MCPDebugServer) Launch(ctx context.Context, request mcp.CallToolRequest
 func (s *MCPDebugServer) Attach(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received attach request")
 
-	pidFloat := request.Params.Arguments["pid"].(float64)
-	pid := int(pidFloat)
+	var pid int
+	if pidFloat, ok := request.Params.Arguments["pid"].(float64); ok {
+		pid = int(pidFloat)
+	} else if spec, ok := request.Params.Arguments["process"].(string); ok && spec != "" {
+		process, err := debugger.ResolveProcess(spec)
+		if err != nil {
+			return newErrorResult("%v", err), nil
+		}
+		pid = process.Pid
+	} else {
+		return newErrorResult("either pid or process is required"), nil
+	}
+
+	client := debugger.NewClient()
+	s.streamOutput(ctx, client)
+	response := client.AttachToProcess(pid)
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
+	}
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Connect(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received connect request")
 
-	response := s.debugClient.AttachToProcess(pid)
+	address := request.Params.Arguments["address"].(string)
+
+	client := debugger.NewClient()
+	response := client.Connect(address)
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
+	}
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) OpenCore(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received open_core request")
+
+	core := request.Params.Arguments["core"].(string)
+	executable := request.Params.Arguments["executable"].(string)
+
+	client := debugger.NewClient()
+	response := client.OpenCore(core, executable)
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
+	}
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) DumpCore(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received dump_core request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	path := request.Params.Arguments["path"].(string)
+
+	response := client.DumpCore(path, s.progressReporter(ctx, request))
 
 	return newToolResultJSON(response)
 }
@@ -253,13 +810,32 @@ func (s *MCPDebugServer) Attach(ctx context.Context, request mcp.CallToolRequest
 func (s *MCPDebugServer) Close(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received close request")
 
-	response, err := s.debugClient.Close()
+	id, client, err := s.lookupSession(request)
 	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	// Don't kill a program someone else started the server for unless asked
+	kill := !client.IsRemote()
+	if killVal, ok := request.Params.Arguments["kill"].(bool); ok {
+		kill = killVal
+	}
+
+	response, err := client.Close(kill)
+	if response == nil {
+		// Close refused and the session stays usable
 		logger.Error("Failed to close debug session", "error", err)
 		return newErrorResult("failed to close debug session: %v", err), nil
 	}
 
-	s.debugClient = debugger.NewClient()
+	// The client is disconnected even when detaching failed, so the session goes
+	if id != "" {
+		s.removeSession(id)
+	}
+	if err != nil {
+		logger.Error("Failed to detach while closing debug session", "error", err)
+		return newErrorResult("debug session closed, but detaching failed: %v", err), nil
+	}
 
 	return newToolResultJSON(response)
 }
@@ -267,10 +843,15 @@ func (s *MCPDebugServer) Close(ctx context.Context, request mcp.CallToolRequest)
 func (s *MCPDebugServer) SetBreakpoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received set_breakpoint request")
 
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
 	file := request.Params.Arguments["file"].(string)
 	line := int(request.Params.Arguments["line"].(float64))
 
-	breakpoint := s.debugClient.SetBreakpoint(file, line)
+	breakpoint := client.SetBreakpoint(file, line)
 
 	return newToolResultJSON(breakpoint)
 }
@@ -278,7 +859,12 @@ func (s *MCPDebugServer) SetBreakpoint(ctx context.Context, request mcp.CallTool
 func (s *MCPDebugServer) ListBreakpoints(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received list_breakpoints request")
 
-	response := s.debugClient.ListBreakpoints()
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	response := client.ListBreakpoints()
 
 	return newToolResultJSON(response)
 }
@@ -286,9 +872,14 @@ func (s *MCPDebugServer) ListBreakpoints(ctx context.Context, request mcp.CallTo
 func (s *MCPDebugServer) RemoveBreakpoint(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received remove_breakpoint request")
 
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
 	id := int(request.Params.Arguments["id"].(float64))
 
-	response := s.debugClient.RemoveBreakpoint(id)
+	response := client.RemoveBreakpoint(id)
 
 	return newToolResultJSON(response)
 }
@@ -307,7 +898,34 @@ func (s *MCPDebugServer) DebugSourceFile(ctx context.Context, request mcp.CallTo
 		}
 	}
 
-	response := s.debugClient.DebugSourceFile(file, args)
+	client := debugger.NewClient()
+	s.streamOutput(ctx, client)
+	response := client.DebugSourceFile(file, args, parseLaunchOptions(request))
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
+	}
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) DebugPackage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received debug_package request")
+
+	pkg := request.Params.Arguments["package"].(string)
+
+	var dir string
+	if dirVal, ok := request.Params.Arguments["dir"].(string); ok {
+		dir = dirVal
+	}
+
+	args := getStringArrayArg(request.Params.Arguments, "args")
+
+	client := debugger.NewClient()
+	s.streamOutput(ctx, client)
+	response := client.DebugPackage(pkg, dir, args, parseLaunchOptions(request))
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
+	}
 
 	return newToolResultJSON(response)
 }
@@ -315,14 +933,24 @@ func (s *MCPDebugServer) DebugSourceFile(ctx context.Context, request mcp.CallTo
 func (s *MCPDebugServer) Continue(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received continue request")
 
-	state := s.debugClient.Continue()
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	state := client.Continue()
 	return newToolResultJSON(state)
 }
 
 func (s *MCPDebugServer) Step(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received step request")
 
-	state := s.debugClient.Step()
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	state := client.Step()
 
 	return newToolResultJSON(state)
 }
@@ -330,7 +958,12 @@ func (s *MCPDebugServer) Step(ctx context.Context, request mcp.CallToolRequest)
 func (s *MCPDebugServer) StepOver(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received step_over request")
 
-	state := s.debugClient.StepOver()
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	state := client.StepOver()
 
 	return newToolResultJSON(state)
 }
@@ -338,13 +971,23 @@ func (s *MCPDebugServer) StepOver(ctx context.Context, request mcp.CallToolReque
 func (s *MCPDebugServer) StepOut(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received step_out request")
 
-	state := s.debugClient.StepOut()
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	state := client.StepOut()
 	return newToolResultJSON(state)
 }
 
 func (s *MCPDebugServer) EvalVariable(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received evaluate_variable request")
 
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
 	name := request.Params.Arguments["name"].(string)
 
 	var depth int
@@ -354,7 +997,117 @@ func (s *MCPDebugServer) EvalVariable(ctx context.Context, request mcp.CallToolR
 		depth = 1
 	}
 
-	response := s.debugClient.EvalVariable(name, depth)
+	callUnwrap, _ := request.Params.Arguments["callUnwrap"].(bool)
+
+	response := client.EvalVariable(name, depth, callUnwrap)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Goroutines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received goroutines request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	response := client.ListGoroutines()
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) Stacktrace(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received stacktrace request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	var goroutineID int64
+	if goroutine, ok := request.Params.Arguments["goroutine"].(float64); ok {
+		goroutineID = int64(goroutine)
+	}
+
+	var depth int
+	if depthVal, ok := request.Params.Arguments["depth"].(float64); ok {
+		depth = int(depthVal)
+	}
+
+	var locals bool
+	if localsVal, ok := request.Params.Arguments["locals"].(bool); ok {
+		locals = localsVal
+	}
+
+	response := client.Stacktrace(goroutineID, depth, locals)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) AnalyzeGoroutines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received analyze_goroutines request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	minBlocked := time.Minute
+	if minBlockedVal, ok := request.Params.Arguments["minBlocked"].(string); ok && minBlockedVal != "" {
+		minBlocked, err = time.ParseDuration(minBlockedVal)
+		if err != nil {
+			return newErrorResult("invalid minBlocked: %v", err), nil
+		}
+	}
+
+	response := client.AnalyzeGoroutines(minBlocked)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) InspectMutex(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received inspect_mutex request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	expression, _ := request.Params.Arguments["expression"].(string)
+
+	response := client.InspectMutex(expression)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SnapshotGoroutines(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received snapshot_goroutines request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	name := request.Params.Arguments["name"].(string)
+
+	response := client.SnapshotGoroutines(name)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) DiffGoroutineSnapshots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received diff_goroutine_snapshots request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	from := request.Params.Arguments["from"].(string)
+	to := request.Params.Arguments["to"].(string)
+
+	response := client.DiffGoroutineSnapshots(from, to)
 
 	return newToolResultJSON(response)
 }
@@ -362,27 +1115,221 @@ func (s *MCPDebugServer) EvalVariable(ctx context.Context, request mcp.CallToolR
 func (s *MCPDebugServer) GetDebuggerOutput(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received get_debugger_output request")
 
-	output := s.debugClient.GetDebuggerOutput()
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	query := types.OutputQuery{}
+	if since, ok := request.Params.Arguments["since"].(float64); ok {
+		sinceLine := int(since)
+		query.Since = &sinceLine
+	}
+	if sinceLastRead, ok := request.Params.Arguments["sinceLastRead"].(bool); ok {
+		query.SinceLastRead = sinceLastRead
+	}
+	if tail, ok := request.Params.Arguments["tail"].(float64); ok {
+		query.Tail = int(tail)
+	}
+	if filter, ok := request.Params.Arguments["filter"].(string); ok {
+		query.Filter = filter
+	}
+	if source, ok := request.Params.Arguments["source"].(string); ok {
+		query.Source = source
+	}
+	if format, ok := request.Params.Arguments["format"].(string); ok {
+		query.Format = format
+	}
+	if level, ok := request.Params.Arguments["level"].(string); ok {
+		query.Level = level
+	}
+	if attrs, ok := request.Params.Arguments["attrs"].(map[string]interface{}); ok {
+		query.Attrs = make(map[string]string, len(attrs))
+		for key, value := range attrs {
+			query.Attrs[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	output := client.GetDebuggerOutput(query)
 
 	return newToolResultJSON(output)
 }
 
+func (s *MCPDebugServer) WriteStdin(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received write_stdin request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	var data string
+	if dataVal, ok := request.Params.Arguments["data"].(string); ok {
+		data = dataVal
+	}
+
+	var closeStdin bool
+	if closeVal, ok := request.Params.Arguments["close"].(bool); ok {
+		closeStdin = closeVal
+	}
+
+	response := client.WriteStdin(data, closeStdin)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) BreakOnOutput(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received break_on_output request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	if remove, ok := request.Params.Arguments["remove"].(float64); ok {
+		return newToolResultJSON(client.ClearOutputBreak(int(remove)))
+	}
+
+	pattern, ok := request.Params.Arguments["pattern"].(string)
+	if !ok || pattern == "" {
+		return newErrorResult("pattern is required unless remove is given"), nil
+	}
+
+	var source string
+	if sourceVal, ok := request.Params.Arguments["source"].(string); ok {
+		source = sourceVal
+	}
+
+	response := client.BreakOnOutput(pattern, source)
+
+	return newToolResultJSON(response)
+}
+
 func (s *MCPDebugServer) DebugTest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	logger.Debug("Received debug_test request")
 
-	testfile := request.Params.Arguments["testfile"].(string)
-	testname := request.Params.Arguments["testname"].(string)
+	target := parseTestTarget(request)
+	if testname, ok := request.Params.Arguments["testname"].(string); ok {
+		target.TestName = testname
+	}
+	if run, ok := request.Params.Arguments["run"].(string); ok {
+		target.Run = run
+	}
+	if stopAtCase, ok := request.Params.Arguments["stopAtCase"].(bool); ok {
+		target.StopAtCase = stopAtCase
+	}
+	if stopOnFailure, ok := request.Params.Arguments["stopOnFailure"].(bool); ok {
+		target.StopOnFailure = stopOnFailure
+	}
 
-	var testflags []string
-	if testflagsVal, ok := request.Params.Arguments["testflags"]; ok && testflagsVal != nil {
-		testflagsArray := testflagsVal.([]interface{})
-		testflags = make([]string, len(testflagsArray))
-		for i, flag := range testflagsArray {
-			testflags[i] = fmt.Sprintf("%v", flag)
-		}
+	testflags := getStringArrayArg(request.Params.Arguments, "testflags")
+
+	client := debugger.NewClient()
+	s.streamOutput(ctx, client)
+	response := client.DebugTest(target, testflags, parseLaunchOptions(request))
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
+	}
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) DebugBenchmark(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received debug_benchmark request")
+
+	target := parseTestTarget(request)
+	target.Benchmark = request.Params.Arguments["benchmark"].(string)
+
+	testflags := getStringArrayArg(request.Params.Arguments, "testflags")
+
+	client := debugger.NewClient()
+	s.streamOutput(ctx, client)
+	response := client.DebugTest(target, testflags, parseLaunchOptions(request))
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
 	}
 
-	response := s.debugClient.DebugTest(testfile, testname, testflags)
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) DebugFuzzCase(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received debug_fuzz_case request")
+
+	target := parseTestTarget(request)
+	target.FuzzCase = request.Params.Arguments["fuzzcase"].(string)
+
+	testflags := getStringArrayArg(request.Params.Arguments, "testflags")
+
+	client := debugger.NewClient()
+	s.streamOutput(ctx, client)
+	response := client.DebugTest(target, testflags, parseLaunchOptions(request))
+	if response.Context.ErrorMessage == "" {
+		response.Session = s.addSession(client)
+	}
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListTests(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_tests request")
+
+	pkg := request.Params.Arguments["package"].(string)
+
+	var dir string
+	if dirVal, ok := request.Params.Arguments["dir"].(string); ok {
+		dir = dirVal
+	}
+
+	response := debugger.ListTests(pkg, dir)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListProcesses(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_processes request")
+
+	var filter string
+	if filterVal, ok := request.Params.Arguments["filter"].(string); ok {
+		filter = filterVal
+	}
+
+	response := debugger.ListProcesses(filter)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListTargets(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_targets request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	response := client.ListTargets()
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) SwitchTarget(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received switch_target request")
+
+	_, client, err := s.lookupSession(request)
+	if err != nil {
+		return newErrorResult("%v", err), nil
+	}
+
+	pid := int(request.Params.Arguments["pid"].(float64))
+
+	response := client.SwitchTarget(pid)
+
+	return newToolResultJSON(response)
+}
+
+func (s *MCPDebugServer) ListSessions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger.Debug("Received list_sessions request")
+
+	response := s.listSessions()
 
 	return newToolResultJSON(response)
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sunfmin/mcp-go-debugger/pkg/debugger"
 	"github.com/sunfmin/mcp-go-debugger/pkg/types"
 )
 
@@ -629,3 +630,156 @@ func TestEvalComplexVariables(t *testing.T) {
 	closeResult, err := server.Close(ctx, closeRequest)
 	expectSuccess(t, closeResult, err, &types.CloseResponse{})
 }
+
+func createErrorChainTestGoFile(t *testing.T) string {
+	tempDir := t.TempDir()
+
+	goFile := filepath.Join(tempDir, "main.go")
+	content := `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var causes = map[int]error{7: errors.New("disk full")}
+
+// lookupError holds no error field; only Unwrap finds what it wraps
+type lookupError struct{ id int }
+
+func (e *lookupError) Error() string { return fmt.Sprintf("lookup %d", e.id) }
+
+func (e *lookupError) Unwrap() error { return causes[e.id] }
+
+func main() {
+	err := fmt.Errorf("save: %w", &lookupError{id: 7})
+	fmt.Println(errors.Is(err, causes[7]))
+}
+`
+	if err := os.WriteFile(goFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	return goFile
+}
+
+func TestEvalErrorChain(t *testing.T) {
+	// Skip test in short mode
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	testFile := createErrorChainTestGoFile(t)
+	server := NewMCPDebugServer("test-version")
+	ctx := context.Background()
+
+	launchRequest := mcp.CallToolRequest{}
+	launchRequest.Params.Arguments = map[string]interface{}{
+		"file": testFile,
+	}
+	debugResult, err := server.DebugSourceFile(ctx, launchRequest)
+	expectSuccess(t, debugResult, err, &types.DebugSourceResponse{})
+
+	setBreakpointRequest := mcp.CallToolRequest{}
+	setBreakpointRequest.Params.Arguments = map[string]interface{}{
+		"file": testFile,
+		"line": float64(findLineNumber(testFile, "fmt.Println(errors.Is(err, causes[7]))")),
+	}
+	breakpointResult, err := server.SetBreakpoint(ctx, setBreakpointRequest)
+	expectSuccess(t, breakpointResult, err, &types.BreakpointResponse{})
+
+	continueResult, err := server.Continue(ctx, mcp.CallToolRequest{})
+	expectSuccess(t, continueResult, err, &types.ContinueResponse{})
+
+	// Allow time for breakpoint to be hit
+	time.Sleep(300 * time.Millisecond)
+
+	// Without callUnwrap no program code runs, so the chain ends at lookupError
+	fieldsRequest := mcp.CallToolRequest{}
+	fieldsRequest.Params.Arguments = map[string]interface{}{
+		"name": "err",
+	}
+	fieldsResult, err := server.EvalVariable(ctx, fieldsRequest)
+	fieldsResponse := &types.EvalVariableResponse{}
+	expectSuccess(t, fieldsResult, err, fieldsResponse)
+	if chain := fieldsResponse.Variable.ErrorChain; len(chain) != 2 || chain[1].Type != "*main.lookupError" {
+		t.Errorf("Expected the chain to end at *main.lookupError, got %+v", chain)
+	}
+
+	evalRequest := mcp.CallToolRequest{}
+	evalRequest.Params.Arguments = map[string]interface{}{
+		"name":       "err",
+		"callUnwrap": true,
+	}
+	evalResult, err := server.EvalVariable(ctx, evalRequest)
+	evalResponse := &types.EvalVariableResponse{}
+	expectSuccess(t, evalResult, err, evalResponse)
+
+	// The cause of lookupError is only reachable by calling its Unwrap
+	want := []string{"*fmt.wrapError", "*main.lookupError", "*errors.errorString"}
+	chain := evalResponse.Variable.ErrorChain
+	if len(chain) != len(want) {
+		t.Fatalf("Expected a chain of %d errors, got %+v", len(want), chain)
+	}
+	for i, typ := range want {
+		if chain[i].Type != typ || chain[i].Depth != i {
+			t.Errorf("Link %d: expected %s at depth %d, got %+v", i, typ, i, chain[i])
+		}
+	}
+	if chain[2].Message != "disk full" {
+		t.Errorf("Expected the cause \"disk full\", got %q", chain[2].Message)
+	}
+
+	closeResult, err := server.Close(ctx, mcp.CallToolRequest{})
+	expectSuccess(t, closeResult, err, &types.CloseResponse{})
+}
+
+func TestSessionRegistry(t *testing.T) {
+	server := NewMCPDebugServer("test-version")
+	ctx := context.Background()
+
+	// Without any session, tools fall back to an idle client
+	continueResult, err := server.Continue(ctx, mcp.CallToolRequest{})
+	continueResponse := &types.ContinueResponse{}
+	expectSuccess(t, continueResult, err, continueResponse)
+	if continueResponse.Context.ErrorMessage != "no active debug session" {
+		t.Errorf("Expected no active debug session error, got: %q", continueResponse.Context.ErrorMessage)
+	}
+
+	first := server.addSession(debugger.NewClient())
+	second := server.addSession(debugger.NewClient())
+	if first == second {
+		t.Fatalf("Expected distinct session IDs, got %s twice", first)
+	}
+
+	listResult, err := server.ListSessions(ctx, mcp.CallToolRequest{})
+	listResponse := &types.SessionListResponse{}
+	expectSuccess(t, listResult, err, listResponse)
+	if len(listResponse.Sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(listResponse.Sessions))
+	}
+	if !listResponse.Sessions[1].Current || listResponse.Sessions[1].ID != second {
+		t.Errorf("Expected session %s to be current, got %+v", second, listResponse.Sessions)
+	}
+
+	// Unknown sessions are rejected
+	unknownRequest := mcp.CallToolRequest{}
+	unknownRequest.Params.Arguments = map[string]interface{}{
+		"session": "does-not-exist",
+	}
+	unknownResult, err := server.Continue(ctx, unknownRequest)
+	if err != nil || !unknownResult.IsError {
+		t.Errorf("Expected error result for unknown session, got %v, %v", getTextContent(unknownResult), err)
+	}
+
+	// Closing the current session falls back to the previous one
+	closeRequest := mcp.CallToolRequest{}
+	closeRequest.Params.Arguments = map[string]interface{}{
+		"session": second,
+	}
+	closeResult, err := server.Close(ctx, closeRequest)
+	expectSuccess(t, closeResult, err, &types.CloseResponse{})
+
+	if id, _, _ := server.lookupSession(mcp.CallToolRequest{}); id != first {
+		t.Errorf("Expected current session to fall back to %s, got %q", first, id)
+	}
+}
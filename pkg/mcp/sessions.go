@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/sunfmin/mcp-go-debugger/pkg/debugger"
+	"github.com/sunfmin/mcp-go-debugger/pkg/types"
+)
+
+// withSessionArg adds the optional session argument shared by all session-scoped tools
+func withSessionArg() mcp.ToolOption {
+	return mcp.WithString("session",
+		mcp.Description("ID of the debug session to use (defaults to the most recently started session)"),
+	)
+}
+
+// addSession registers a connected client and makes it the default session
+func (s *MCPDebugServer) addSession(client *debugger.Client) string {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	s.nextSessionID++
+	id := strconv.Itoa(s.nextSessionID)
+	s.sessions[id] = client
+	s.sessionOrder = append(s.sessionOrder, id)
+	s.currentSession = id
+
+	return id
+}
+
+// removeSession drops a session and falls back to the most recently started remaining one
+func (s *MCPDebugServer) removeSession(id string) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	delete(s.sessions, id)
+	for i, sid := range s.sessionOrder {
+		if sid == id {
+			s.sessionOrder = append(s.sessionOrder[:i], s.sessionOrder[i+1:]...)
+			break
+		}
+	}
+
+	if s.currentSession == id {
+		s.currentSession = ""
+		if n := len(s.sessionOrder); n > 0 {
+			s.currentSession = s.sessionOrder[n-1]
+		}
+	}
+}
+
+// sessionIDOf returns the ID of the session a client belongs to, or "" before it is registered
+func (s *MCPDebugServer) sessionIDOf(client *debugger.Client) string {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	for id, c := range s.sessions {
+		if c == client {
+			return id
+		}
+	}
+	return ""
+}
+
+// lookupSession resolves the session argument of a request to a client.
+// Without a session argument the current session is used; when no session
+// exists an idle client is returned so tools report "no active debug session".
+func (s *MCPDebugServer) lookupSession(request mcp.CallToolRequest) (string, *debugger.Client, error) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	id := s.currentSession
+	if sessionVal, ok := request.Params.Arguments["session"]; ok && sessionVal != nil {
+		id = fmt.Sprintf("%v", sessionVal)
+		if _, exists := s.sessions[id]; !exists {
+			return "", nil, fmt.Errorf("unknown debug session %q", id)
+		}
+	}
+
+	if client, exists := s.sessions[id]; exists {
+		return id, client, nil
+	}
+
+	return "", debugger.NewClient(), nil
+}
+
+// listSessions returns information about every registered session
+func (s *MCPDebugServer) listSessions() types.SessionListResponse {
+	s.sessionsMu.Lock()
+	ids := make([]string, len(s.sessionOrder))
+	copy(ids, s.sessionOrder)
+	clients := make(map[string]*debugger.Client, len(s.sessions))
+	for id, client := range s.sessions {
+		clients[id] = client
+	}
+	current := s.currentSession
+	s.sessionsMu.Unlock()
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+
+	sessions := make([]types.SessionInfo, 0, len(ids))
+	for _, id := range ids {
+		info := clients[id].SessionInfo(id)
+		info.Current = id == current
+		sessions = append(sessions, info)
+	}
+
+	summary := "No active debug sessions"
+	if len(sessions) > 0 {
+		summary = fmt.Sprintf("%d debug session(s), current session is %s", len(sessions), current)
+	}
+
+	return types.SessionListResponse{
+		Status:   "success",
+		Sessions: sessions,
+		Summary:  summary,
+	}
+}
@@ -16,6 +16,11 @@ type DebugContext struct {
 	// LLM-friendly additions
 	StopReason   string `json:"stopReason,omitempty"` // Why the program stopped, in human terms
 	ErrorMessage string `json:"error,omitempty"`      // Error message if any
+	// Set once the program has exited
+	Exited       bool     `json:"exited,omitempty"`       // The program has exited
+	ExitCode     *int     `json:"exitCode,omitempty"`     // Exit status of the program
+	LastLocation *string  `json:"lastLocation,omitempty"` // Last location the program stopped at before exiting
+	LastOutput   []string `json:"lastOutput,omitempty"`   // Last output lines before exiting, stderr lines prefixed
 }
 
 // Variable represents a program variable with LLM-friendly additions
@@ -29,6 +34,37 @@ type Variable struct {
 	Type  string `json:"type"`  // Type in human-readable format
 	Scope string `json:"scope"` // Variable scope (local, global, etc)
 	Kind  string `json:"kind"`  // High-level kind description
+
+	Channel    *ChannelInfo `json:"channel,omitempty"`    // Runtime state of chan values
+	ErrorChain []ErrorLink  `json:"errorChain,omitempty"` // An error and the errors it wraps, outermost first
+}
+
+// ErrorLink is one error in the wrapping chain of an error
+type ErrorLink struct {
+	Depth    int      `json:"depth"` // 0 for the error itself, 1 for the errors it wraps, and so on
+	Type     string   `json:"type"`  // Concrete type
+	Message  string   `json:"message,omitempty"`
+	Location string   `json:"location,omitempty"` // Where it was created, when it recorded a stack
+	Stack    []string `json:"stack,omitempty"`    // Creation stack, innermost frame first
+}
+
+// ChannelInfo is the runtime state of a channel, read from runtime.hchan
+type ChannelInfo struct {
+	Address     string          `json:"address,omitempty"` // Address of the hchan
+	ElemType    string          `json:"elemType"`
+	Nil         bool            `json:"nil,omitempty"`
+	Capacity    int             `json:"capacity"`
+	Len         int             `json:"len"` // Values in the buffer
+	Closed      bool            `json:"closed"`
+	Buffered    []string        `json:"buffered,omitempty"`    // Buffered values, next to be received first
+	SendWaiters []ChannelWaiter `json:"sendWaiters,omitempty"` // Goroutines parked in sendq
+	RecvWaiters []ChannelWaiter `json:"recvWaiters,omitempty"` // Goroutines parked in recvq
+}
+
+// ChannelWaiter is a goroutine parked on a channel operation
+type ChannelWaiter struct {
+	GoroutineID int64  `json:"goroutineId"`
+	Location    string `json:"location,omitempty"` // Innermost user code frame
 }
 
 // Breakpoint represents a breakpoint with LLM-friendly additions
@@ -59,13 +95,69 @@ type DebuggerOutput struct {
 	ExitCode      int          `json:"exitCode"`      // Program exit code if available
 }
 
+// LaunchOptions controls how a debuggee is built and started
+type LaunchOptions struct {
+	Cwd        string            `json:"cwd,omitempty"`        // Working directory of the debuggee
+	Env        map[string]string `json:"env,omitempty"`        // Environment variables to add or override
+	UnsetEnv   []string          `json:"unsetEnv,omitempty"`   // Environment variables to remove
+	ClearEnv   bool              `json:"clearEnv,omitempty"`   // Start from an empty environment instead of inheriting
+	BuildFlags []string          `json:"buildFlags,omitempty"` // Extra flags passed to go build
+	Tags       []string          `json:"tags,omitempty"`       // Build tags
+	Race       bool              `json:"race,omitempty"`       // Build with the race detector
+	Stdin      string            `json:"stdin,omitempty"`      // File to read standard input from
+	StdinText  string            `json:"stdinText,omitempty"`  // Inline standard input, followed by EOF unless StdinPipe is set
+	StdinPipe  bool              `json:"stdinPipe,omitempty"`  // Keep standard input open for write_stdin
+
+	FollowExec      bool   `json:"followExec,omitempty"`      // Also debug child processes the program executes (Linux)
+	FollowExecRegex string `json:"followExecRegex,omitempty"` // Only follow children whose command line matches; implies FollowExec
+}
+
+// TestTarget selects the test package and the tests to run under the debugger
+type TestTarget struct {
+	TestFile      string `json:"testFile,omitempty"`      // Test file; its directory is the package under test
+	Package       string `json:"package,omitempty"`       // Package directory (./pkg/foo) or import path
+	Dir           string `json:"dir,omitempty"`           // Directory to resolve Package from
+	TestName      string `json:"testName,omitempty"`      // Test name, optionally with a subtest path like TestX/case_3
+	Run           string `json:"run,omitempty"`           // Raw -test.run regular expression, used unmodified
+	StopAtCase    bool   `json:"stopAtCase,omitempty"`    // Stop at the start of the test or t.Run body matching TestName
+	Benchmark     string `json:"benchmark,omitempty"`     // Benchmark to run once instead of tests, optionally with a sub-benchmark path
+	FuzzCase      string `json:"fuzzCase,omitempty"`      // Corpus entry under testdata/fuzz/FuzzX/ to replay, as FuzzX/file or a path
+	StopOnFailure bool   `json:"stopOnFailure,omitempty"` // Stop where the test calls t.Error, t.Fatal, t.Fail or t.Skip
+}
+
+// TestFunction is a test, benchmark, fuzz target or example found in a package
+type TestFunction struct {
+	Name     string    `json:"name"`               // Function name, usable as debug_test testname
+	Kind     string    `json:"kind"`               // test, benchmark, fuzz or example
+	File     string    `json:"file"`               // File declaring the function
+	Line     int       `json:"line"`               // Line of the declaration
+	Subtests []Subtest `json:"subtests,omitempty"` // Statically known t.Run / b.Run cases
+}
+
+// Subtest is a t.Run case whose name could be determined from the source
+type Subtest struct {
+	Name string `json:"name"` // Full name like TestX/case_3, usable as debug_test testname
+	Line int    `json:"line"` // Line of the t.Run call
+}
+
+// BuildDiagnostic is a single compiler or vet error reported by a debug build
+type BuildDiagnostic struct {
+	File    string `json:"file,omitempty"`    // Absolute path of the offending file
+	Line    int    `json:"line,omitempty"`    // 1-based line number
+	Column  int    `json:"column,omitempty"`  // 1-based column, 0 if not reported
+	Message string `json:"message"`           // Diagnostic text
+	Package string `json:"package,omitempty"` // Package being built when the error was reported
+}
+
 // Operation-specific responses
 
 type LaunchResponse struct {
-	Context  *DebugContext `json:"context"`
-	Program  string        `json:"program"`
-	Args     []string      `json:"args"`
-	ExitCode int           `json:"exitCode"`
+	Session  string         `json:"session,omitempty"` // Session ID to pass to subsequent tools
+	Context  *DebugContext  `json:"context"`
+	Program  string         `json:"program"`
+	Args     []string       `json:"args"`
+	Options  *LaunchOptions `json:"options,omitempty"`  // Effective launch settings
+	ExitCode *int           `json:"exitCode,omitempty"` // Exit status if the program already exited
 }
 
 type BreakpointResponse struct {
@@ -95,26 +187,306 @@ type EvalVariableResponse struct {
 }
 
 type ContinueResponse struct {
-	Status  string       `json:"status"`
-	Context DebugContext `json:"context"`
+	Status      string          `json:"status"`
+	Context     DebugContext    `json:"context"`
+	TestFailure *TestFailure    `json:"testFailure,omitempty"` // Set when stopped at a test failure with stopOnFailure
+	OutputBreak *OutputBreakHit `json:"outputBreak,omitempty"` // Set when halted because output matched break_on_output
+}
+
+// OutputBreakpoint halts the program when it prints a line matching Pattern
+type OutputBreakpoint struct {
+	ID       int    `json:"id"`               // Output breakpoint ID
+	Pattern  string `json:"pattern"`          // Regular expression matched against each line
+	Source   string `json:"source,omitempty"` // stdout or stderr; both when empty
+	HitCount int    `json:"hitCount"`         // Number of lines matched
+}
+
+// OutputBreakHit describes the output line that halted the program
+type OutputBreakHit struct {
+	ID                int              `json:"id"`                          // Output breakpoint that matched
+	Pattern           string           `json:"pattern"`                     // Its regular expression
+	Source            string           `json:"source"`                      // stdout or stderr
+	Line              string           `json:"line"`                        // The matching line
+	LineNumber        int              `json:"lineNumber"`                  // Its number, usable as a get_debugger_output cursor
+	Timestamp         time.Time        `json:"timestamp"`                   // When the line was captured
+	WritingGoroutines []GoroutineStack `json:"writingGoroutines,omitempty"` // Goroutines in a write syscall when halted, best-effort
+}
+
+// GoroutineStack is the call stack of one goroutine, innermost frame first
+type GoroutineStack struct {
+	ID     int64    `json:"id"`     // Goroutine ID
+	Frames []string `json:"frames"` // "function at file:line" per frame
+}
+
+type OutputBreakpointResponse struct {
+	Status            string             `json:"status"`
+	Context           DebugContext       `json:"context"`
+	OutputBreakpoint  *OutputBreakpoint  `json:"outputBreakpoint,omitempty"`  // The added or removed output breakpoint
+	OutputBreakpoints []OutputBreakpoint `json:"outputBreakpoints,omitempty"` // All active output breakpoints
+	Summary           string             `json:"summary,omitempty"`
+}
+
+// TestFailure describes a stop at t.Error, t.Fatal, t.Fail, t.Skip or a related method
+type TestFailure struct {
+	Method   string     `json:"method"`             // Testing method called, e.g. Errorf or FailNow
+	Skipped  bool       `json:"skipped,omitempty"`  // The test is being skipped rather than failed
+	TestName string     `json:"testName,omitempty"` // Full name of the test reporting the failure
+	Message  string     `json:"message,omitempty"`  // Message formatted from the call's arguments
+	File     string     `json:"file,omitempty"`     // File of the calling frame in the test
+	Line     int        `json:"line,omitempty"`     // Line of the call
+	Function string     `json:"function,omitempty"` // Function making the call, the test or a helper
+	Locals   []Variable `json:"locals,omitempty"`   // Arguments and locals of the calling frame
 }
 
 type CloseResponse struct {
 	Status   string       `json:"status"`
 	Context  DebugContext `json:"context"`
-	ExitCode int          `json:"exitCode"` // Program exit code
-	Summary  string       `json:"summary"`  // Session summary for LLM
+	ExitCode *int         `json:"exitCode,omitempty"` // Exit status of the program; absent when it was killed on close
+	Summary  string       `json:"summary"`            // Session summary for LLM
+}
+
+// OutputQuery selects the program output returned by get_debugger_output
+type OutputQuery struct {
+	Since         *int   // Return lines numbered Since or later, from a previous NextCursor
+	SinceLastRead bool   // Return lines captured after the previous read
+	Tail          int    // Return only the last Tail selected lines
+	Filter        string // Regular expression lines must match
+	Source        string // Only "stdout" or "stderr" lines
+
+	Format string            // Parse lines as "json", "logfmt" or "auto" structured log records
+	Level  string            // Only records at this level or above, with Format
+	Attrs  map[string]string // Only records with these attribute values, with Format
+}
+
+// LogRecord is an output line parsed as a structured log entry
+type LogRecord struct {
+	LineNumber   int               `json:"lineNumber"`             // Output line number
+	Source       string            `json:"source"`                 // stdout or stderr
+	Time         string            `json:"time,omitempty"`         // Timestamp as logged
+	Level        string            `json:"level,omitempty"`        // Lowercased level, with aliases such as "warning" normalized
+	Message      string            `json:"message"`                // Log message, or the raw line when unstructured
+	Attrs        map[string]string `json:"attrs,omitempty"`        // Remaining fields; nested groups use dotted keys
+	Unstructured bool              `json:"unstructured,omitempty"` // The line did not parse in the requested format
+}
+
+type WriteStdinResponse struct {
+	Status       string       `json:"status"`
+	Context      DebugContext `json:"context"`
+	BytesWritten int          `json:"bytesWritten"`     // Bytes written to the program's stdin
+	Closed       bool         `json:"closed,omitempty"` // Stdin was closed, the program reads EOF
 }
 
 type DebuggerOutputResponse struct {
 	Status        string       `json:"status"`
 	Context       DebugContext `json:"context"`
-	Stdout        string       `json:"stdout"`        // Captured standard output
-	Stderr        string       `json:"stderr"`        // Captured standard error
-	OutputSummary string       `json:"outputSummary"` // Brief summary of output for LLM
+	Stdout        string       `json:"stdout"`               // Selected standard output lines
+	Stderr        string       `json:"stderr"`               // Selected standard error lines
+	OutputSummary string       `json:"outputSummary"`        // Brief summary of output for LLM
+	Lines         int          `json:"lines"`                // Number of lines returned
+	NextCursor    int          `json:"nextCursor"`           // Pass as since to read only newer lines
+	Dropped       int          `json:"dropped,omitempty"`    // Oldest lines discarded to keep the buffer bounded
+	Missed        int          `json:"missed,omitempty"`     // Lines after the requested cursor that were already discarded
+	Unstreamed    int          `json:"unstreamed,omitempty"` // Lines not streamed as notifications because the client fell behind
+	Records       []LogRecord  `json:"records,omitempty"`    // Selected lines parsed as structured logs, with a format
+}
+
+type OpenCoreResponse struct {
+	Session    string        `json:"session,omitempty"` // Session ID to pass to subsequent tools
+	Status     string        `json:"status"`
+	Context    *DebugContext `json:"context"`
+	CoreFile   string        `json:"coreFile"`   // Absolute path of the core dump
+	Executable string        `json:"executable"` // Binary that produced the core
+	Summary    string        `json:"summary,omitempty"`
+}
+
+// Goroutine is a goroutine with its state and user-level location
+type Goroutine struct {
+	ID             int64             `json:"id"`
+	Status         string            `json:"status"`                   // running, runnable, waiting, syscall, etc.
+	WaitReason     string            `json:"waitReason,omitempty"`     // Why a waiting goroutine is blocked, such as "chan receive"
+	Location       string            `json:"location"`                 // Current location outside the runtime
+	StartLocation  string            `json:"startLocation,omitempty"`  // Function the goroutine started in
+	GoStatementLoc string            `json:"goStatementLoc,omitempty"` // The go statement that created it
+	ThreadID       int               `json:"threadId,omitempty"`       // Thread running the goroutine, if any
+	Labels         map[string]string `json:"labels,omitempty"`         // pprof labels
+	Selected       bool              `json:"selected,omitempty"`       // The goroutine the debugger is stopped in
+}
+
+type GoroutinesResponse struct {
+	Status     string       `json:"status"`
+	Context    DebugContext `json:"context"`
+	Goroutines []Goroutine  `json:"goroutines"`
+	Total      int          `json:"total"`
+	Summary    string       `json:"summary,omitempty"` // Counts by status
+}
+
+// StackFrame is one frame of a goroutine's call stack
+type StackFrame struct {
+	Index     int        `json:"index"`               // 0 is the innermost frame
+	Function  string     `json:"function"`            // Fully qualified function name
+	Location  string     `json:"location"`            // "function at file:line"
+	Variables []Variable `json:"variables,omitempty"` // Arguments and locals, when requested
+	Error     string     `json:"error,omitempty"`     // Why the frame could not be fully read
+}
+
+// BlockedGroup is a set of goroutines blocked for the same reason at the same place
+type BlockedGroup struct {
+	WaitReason   string   `json:"waitReason"`          // Such as "chan receive" or "sync.Mutex.Lock"
+	Location     string   `json:"location"`            // Where they block, outside the runtime
+	Count        int      `json:"count"`               // Goroutines in the group
+	GoroutineIDs []int64  `json:"goroutineIds"`        // The first 20 of them
+	Resources    []string `json:"resources,omitempty"` // Channels or mutexes waited on, like "mutex 0xc000012345"
+}
+
+// BlockedGoroutine is a blocked goroutine with what it waits on
+type BlockedGoroutine struct {
+	Goroutine
+	Resource     string   `json:"resource,omitempty"`   // Channel or mutex waited on, when it can be determined
+	BlockedFor   string   `json:"blockedFor,omitempty"` // Approximate time blocked; the runtime records it at the next GC
+	BlockedNanos int64    `json:"-"`
+	Stack        []string `json:"stack,omitempty"` // Call stack, innermost frame first
+}
+
+// DeadlockCycle is a set of goroutines each waiting on a resource another one refers to
+type DeadlockCycle struct {
+	Goroutines  []int64  `json:"goroutines"`
+	Resources   []string `json:"resources"`   // What each goroutine waits on, in the same order
+	Description string   `json:"description"` // The cycle in words
+}
+
+// GoroutineAnalysis summarizes what blocked goroutines wait on
+type GoroutineAnalysis struct {
+	Total       int                `json:"total"`                 // All goroutines
+	Blocked     int                `json:"blocked"`               // Blocked goroutines, excluding runtime workers
+	AllBlocked  bool               `json:"allBlocked,omitempty"`  // No goroutine can make progress
+	Groups      []BlockedGroup     `json:"groups,omitempty"`      // Largest group first
+	Deadlocks   []DeadlockCycle    `json:"deadlocks,omitempty"`   // Likely wait cycles
+	LongBlocked []BlockedGoroutine `json:"longBlocked,omitempty"` // Blocked longer than the threshold, longest first
+	Suspects    []BlockedGoroutine `json:"suspects,omitempty"`    // Most likely deadlock participants, with stacks
+}
+
+type AnalyzeGoroutinesResponse struct {
+	Status   string             `json:"status"`
+	Context  DebugContext       `json:"context"`
+	Analysis *GoroutineAnalysis `json:"analysis,omitempty"`
+	Summary  string             `json:"summary,omitempty"`
+}
+
+// MutexState is the decoded state of a sync.Mutex or sync.RWMutex
+type MutexState struct {
+	Address         string             `json:"address"`
+	Kind            string             `json:"kind"` // mutex or rwmutex
+	Locked          bool               `json:"locked"`
+	Woken           bool               `json:"woken,omitempty"`    // A woken waiter is about to retry
+	Starving        bool               `json:"starving,omitempty"` // Ownership is handed directly to waiters
+	WaiterCount     int                `json:"waiterCount"`        // Waiters counted in the state bits
+	Readers         int                `json:"readers,omitempty"`  // Readers holding an RWMutex
+	ReadersWaiting  int                `json:"readersWaiting,omitempty"`
+	WriterPending   bool               `json:"writerPending,omitempty"`
+	Waiting         []BlockedGoroutine `json:"waiting,omitempty"`         // Goroutines blocked on it
+	PossibleHolders []BlockedGoroutine `json:"possibleHolders,omitempty"` // Goroutines whose frames refer to it without waiting, with stacks
+}
+
+type InspectMutexResponse struct {
+	Status  string       `json:"status"`
+	Context DebugContext `json:"context"`
+	Mutexes []MutexState `json:"mutexes,omitempty"`
+	Summary string       `json:"summary,omitempty"`
+}
+
+type GoroutineSnapshotResponse struct {
+	Status    string       `json:"status"`
+	Context   DebugContext `json:"context"`
+	Name      string       `json:"name"`
+	Count     int          `json:"count"`               // Goroutines recorded
+	TakenAt   time.Time    `json:"takenAt"`             // When the snapshot was taken
+	Snapshots []string     `json:"snapshots,omitempty"` // Names of all snapshots in the session
+	Summary   string       `json:"summary,omitempty"`
+}
+
+// GoroutineDelta is the change in number of goroutines created at the same
+// place and blocked in the same stack
+type GoroutineDelta struct {
+	CreatedBy     string   `json:"createdBy,omitempty"`     // The go statement that started them
+	StartLocation string   `json:"startLocation,omitempty"` // Function they started in
+	Location      string   `json:"location"`                // Current location of the first of them
+	WaitReason    string   `json:"waitReason,omitempty"`
+	Stack         []string `json:"stack,omitempty"` // Functions of their stack, innermost first
+	Before        int      `json:"before"`          // Count in the first snapshot
+	After         int      `json:"after"`           // Count in the second snapshot
+	Delta         int      `json:"delta"`           // After minus Before
+	GoroutineIDs  []int64  `json:"goroutineIds"`    // Goroutines only in one of the snapshots, up to 20
+}
+
+type GoroutineDiffResponse struct {
+	Status      string           `json:"status"`
+	Context     DebugContext     `json:"context"`
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Appeared    []GoroutineDelta `json:"appeared,omitempty"`    // Groups that grew, largest growth first
+	Disappeared []GoroutineDelta `json:"disappeared,omitempty"` // Groups that shrank
+	Summary     string           `json:"summary,omitempty"`
+}
+
+type StacktraceResponse struct {
+	Status      string       `json:"status"`
+	Context     DebugContext `json:"context"`
+	GoroutineID int64        `json:"goroutineId"`
+	Frames      []StackFrame `json:"frames"`
+	Summary     string       `json:"summary,omitempty"`
+}
+
+// DumpProgress reports how much of a core dump has been written
+type DumpProgress struct {
+	ThreadsDone  int    `json:"threadsDone"`
+	ThreadsTotal int    `json:"threadsTotal"`
+	MemDone      uint64 `json:"memDone"`  // Bytes of memory written
+	MemTotal     uint64 `json:"memTotal"` // Bytes of memory to write
+}
+
+// CoreDump describes a core file written from a live session
+type CoreDump struct {
+	Path       string `json:"path"`                 // Absolute path of the core file
+	Executable string `json:"executable,omitempty"` // Binary to pass to open_core with it
+	Size       int64  `json:"size,omitempty"`       // File size in bytes; unknown when written on a remote host
+	Threads    int    `json:"threads"`              // Threads written
+	Memory     uint64 `json:"memory"`               // Bytes of memory written
+	Duration   string `json:"duration"`             // How long the dump took
+}
+
+type DumpCoreResponse struct {
+	Status   string       `json:"status"`
+	Context  DebugContext `json:"context"`
+	CoreDump *CoreDump    `json:"coreDump,omitempty"`
+	Summary  string       `json:"summary,omitempty"`
+}
+
+type ConnectResponse struct {
+	Session string        `json:"session,omitempty"` // Session ID to pass to subsequent tools
+	Status  string        `json:"status"`
+	Context *DebugContext `json:"context"`
+	Address string        `json:"address"` // Address of the Delve server
+	Pid     int           `json:"pid"`     // Process ID of the target
+	Summary string        `json:"summary,omitempty"`
+}
+
+// Target is a process debugged in a session, such as a child followed after exec
+type Target struct {
+	Pid             int     `json:"pid"`
+	CmdLine         string  `json:"cmdLine"`
+	Selected        bool    `json:"selected,omitempty"`        // Stepping and evaluation apply to this target
+	CurrentLocation *string `json:"currentLocation,omitempty"` // Where its current thread is stopped
+}
+
+type TargetsResponse struct {
+	Status  string       `json:"status"`
+	Context DebugContext `json:"context"`
+	Targets []Target     `json:"targets"`
+	Summary string       `json:"summary,omitempty"`
 }
 
 type AttachResponse struct {
+	Session string        `json:"session,omitempty"` // Session ID to pass to subsequent tools
 	Status  string        `json:"status"`
 	Context *DebugContext `json:"context"`
 	Pid     int           `json:"pid"`
@@ -123,32 +495,104 @@ type AttachResponse struct {
 }
 
 type DebugSourceResponse struct {
-	Status      string        `json:"status"`
-	Context     *DebugContext `json:"context"`
-	SourceFile  string        `json:"sourceFile"`
-	DebugBinary string        `json:"debugBinary"`
-	Args        []string      `json:"args"`
+	Session      string            `json:"session,omitempty"` // Session ID to pass to subsequent tools
+	Status       string            `json:"status"`
+	Context      *DebugContext     `json:"context"`
+	SourceFile   string            `json:"sourceFile"`
+	DebugBinary  string            `json:"debugBinary"`
+	Args         []string          `json:"args"`
+	BuildCommand string            `json:"buildCommand"`
+	BuildErrors  []BuildDiagnostic `json:"buildErrors,omitempty"` // Parsed compiler errors if the build failed
+	Options      *LaunchOptions    `json:"options,omitempty"`     // Effective build and launch settings
+}
+
+type DebugPackageResponse struct {
+	Session      string            `json:"session,omitempty"` // Session ID to pass to subsequent tools
+	Status       string            `json:"status"`
+	Context      *DebugContext     `json:"context"`
+	Package      string            `json:"package"`             // Package as requested
+	ImportPath   string            `json:"importPath"`          // Resolved import path
+	PackageDir   string            `json:"packageDir"`          // Directory containing the package sources
+	Module       string            `json:"module,omitempty"`    // Module path the package belongs to
+	ModuleDir    string            `json:"moduleDir,omitempty"` // Root directory of that module
+	Workspace    string            `json:"workspace,omitempty"` // go.work file in effect, if any
+	DebugBinary  string            `json:"debugBinary"`
+	BuildCommand string            `json:"buildCommand"`
+	BuildOutput  string            `json:"buildOutput"`
+	BuildErrors  []BuildDiagnostic `json:"buildErrors,omitempty"` // Parsed compiler errors if the build failed
+	Args         []string          `json:"args"`
+	Options      *LaunchOptions    `json:"options,omitempty"` // Effective build and launch settings
 }
 
 type DebugTestResponse struct {
-	Status       string        `json:"status"`
-	Context      *DebugContext `json:"context"`
-	TestFile     string        `json:"testFile"`
-	TestName     string        `json:"testName"`
-	BuildCommand string        `json:"buildCommand"`
-	BuildOutput  string        `json:"buildOutput"`
-	DebugBinary  string        `json:"debugBinary"`
-	Process      *Process      `json:"process"`
-	TestFlags    []string      `json:"testFlags"`
+	Session      string            `json:"session,omitempty"` // Session ID to pass to subsequent tools
+	Status       string            `json:"status"`
+	Context      *DebugContext     `json:"context"`
+	TestFile     string            `json:"testFile"`
+	TestName     string            `json:"testName"`
+	Package      string            `json:"package,omitempty"`      // Package under test as requested
+	PackageDir   string            `json:"packageDir,omitempty"`   // Directory the test binary was built from
+	RunPattern   string            `json:"runPattern,omitempty"`   // Effective -test.run pattern
+	BenchPattern string            `json:"benchPattern,omitempty"` // Effective -test.bench pattern when debugging a benchmark
+	FuzzCaseFile string            `json:"fuzzCaseFile,omitempty"` // Corpus file replayed when debugging a fuzz case
+	StoppedAt    string            `json:"stoppedAt,omitempty"`    // Test case the debugger stopped in, with stopAtCase
+	TestFailure  *TestFailure      `json:"testFailure,omitempty"`  // Failure the debugger stopped at, with stopOnFailure
+	BuildCommand string            `json:"buildCommand"`
+	BuildOutput  string            `json:"buildOutput"`
+	BuildErrors  []BuildDiagnostic `json:"buildErrors,omitempty"` // Parsed compiler errors if the build failed
+	VetErrors    []BuildDiagnostic `json:"vetErrors,omitempty"`   // Vet findings reported by the test build
+	DebugBinary  string            `json:"debugBinary"`
+	Process      *Process          `json:"process"`
+	TestFlags    []string          `json:"testFlags"`
+	Options      *LaunchOptions    `json:"options,omitempty"` // Effective build and launch settings
+}
+
+// SessionInfo describes one debug session managed by the server
+type SessionInfo struct {
+	ID              string    `json:"id"`                        // Session ID
+	Target          string    `json:"target"`                    // Program or binary being debugged
+	Pid             int       `json:"pid"`                       // Process ID of the debuggee
+	State           string    `json:"state"`                     // running, stopped, exited, etc.
+	StartedAt       time.Time `json:"startedAt"`                 // When the session was established
+	Uptime          string    `json:"uptime"`                    // Human-readable session age
+	CurrentLocation *string   `json:"currentLocation,omitempty"` // Current execution position if stopped
+	ExitCode        *int      `json:"exitCode,omitempty"`        // Exit status once the program has exited
+	Current         bool      `json:"current"`                   // Whether tools default to this session
+}
+
+type SessionListResponse struct {
+	Status   string        `json:"status"`
+	Sessions []SessionInfo `json:"sessions"`
+	Summary  string        `json:"summary"` // Brief description for LLM
+}
+
+type ListTestsResponse struct {
+	Status     string         `json:"status"`
+	Context    DebugContext   `json:"context"`
+	Package    string         `json:"package"`    // Package as requested
+	ImportPath string         `json:"importPath"` // Resolved import path
+	PackageDir string         `json:"packageDir"` // Directory containing the package sources
+	Tests      []TestFunction `json:"tests"`
+	Summary    string         `json:"summary"` // Counts per kind for LLM
 }
 
 // Process represents a debugged process with LLM-friendly additions
 type Process struct {
-	Pid         int      `json:"pid"`         // Process ID
-	Name        string   `json:"name"`        // Process name
-	CmdLine     []string `json:"cmdLine"`     // Command line arguments
-	Status      string   `json:"status"`      // Process status (running, stopped, etc.)
-	Summary     string   `json:"summary"`     // Brief description of process state
-	ExitCode    int      `json:"exitCode"`    // Exit code if process has terminated
-	ExitMessage string   `json:"exitMessage"` // Exit message if process has terminated
+	Pid         int      `json:"pid"`                  // Process ID
+	Name        string   `json:"name"`                 // Process name
+	CmdLine     []string `json:"cmdLine"`              // Command line arguments
+	Executable  string   `json:"executable,omitempty"` // Path of the running binary
+	GoVersion   string   `json:"goVersion,omitempty"`  // Go version the binary was built with
+	Module      string   `json:"module,omitempty"`     // Main package path from the build info
+	Status      string   `json:"status"`               // Process status (running, stopped, etc.)
+	Summary     string   `json:"summary"`              // Brief description of process state
+	ExitCode    int      `json:"exitCode"`             // Exit code if process has terminated
+	ExitMessage string   `json:"exitMessage"`          // Exit message if process has terminated
+}
+
+type ListProcessesResponse struct {
+	Status    string       `json:"status"`
+	Context   DebugContext `json:"context"`
+	Processes []Process    `json:"processes"`
+	Summary   string       `json:"summary"`
 }